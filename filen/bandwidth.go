@@ -0,0 +1,85 @@
+package filen
+
+import (
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
+	"slices"
+	"time"
+)
+
+// BandwidthLimits configures the rates at which file chunks are uploaded and downloaded.
+// The limits are shared by all transfers of a Filen instance.
+type BandwidthLimits struct {
+	Upload        int64 // the upload rate in bytes per second (zero means unlimited)
+	UploadBurst   int64 // how many bytes may be uploaded at once after being idle (defaults to Upload)
+	Download      int64 // the download rate in bytes per second (zero means unlimited)
+	DownloadBurst int64 // how many bytes may be downloaded at once after being idle (defaults to Download)
+}
+
+// A BandwidthRule applies different [BandwidthLimits] during a daily time window, e.g. during business hours.
+type BandwidthRule struct {
+	Weekdays []time.Weekday // the days on which the window starts (every day if empty)
+	Start    time.Duration  // the start of the window as offset from midnight (local time)
+	End      time.Duration  // the end of the window as offset from midnight; windows with End before Start extend past midnight
+	Limits   BandwidthLimits
+}
+
+// matches returns whether the rule applies at the given time.
+func (rule BandwidthRule) matches(now time.Time) bool {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	offset := now.Sub(midnight)
+	appliesOn := func(day time.Weekday) bool {
+		return len(rule.Weekdays) == 0 || slices.Contains(rule.Weekdays, day)
+	}
+	if rule.Start <= rule.End {
+		return appliesOn(now.Weekday()) && offset >= rule.Start && offset < rule.End
+	} else {
+		// the window started either today or yesterday
+		return (appliesOn(now.Weekday()) && offset >= rule.Start) ||
+			(appliesOn(midnight.AddDate(0, 0, -1).Weekday()) && offset < rule.End)
+	}
+}
+
+// SetBandwidthLimits sets the limits that apply whenever no rule of the bandwidth schedule matches.
+// The change takes effect immediately, including for transfers that are already running.
+func (filen *Filen) SetBandwidthLimits(limits BandwidthLimits) {
+	filen.bandwidthMu.Lock()
+	defer filen.bandwidthMu.Unlock()
+	filen.bandwidthLimits = limits
+}
+
+// SetBandwidthSchedule sets rules that override the limits set by [Filen.SetBandwidthLimits] during certain times.
+// If multiple rules match, the first one is applied. Pass nil to remove the schedule.
+func (filen *Filen) SetBandwidthSchedule(rules []BandwidthRule) {
+	filen.bandwidthMu.Lock()
+	defer filen.bandwidthMu.Unlock()
+	filen.bandwidthSchedule = slices.Clone(rules)
+}
+
+// BandwidthLimits returns the limits that currently apply, taking the schedule into account.
+func (filen *Filen) BandwidthLimits() BandwidthLimits {
+	return filen.bandwidthLimitsAt(time.Now())
+}
+
+func (filen *Filen) bandwidthLimitsAt(now time.Time) BandwidthLimits {
+	filen.bandwidthMu.Lock()
+	defer filen.bandwidthMu.Unlock()
+	for _, rule := range filen.bandwidthSchedule {
+		if rule.matches(now) {
+			return rule.Limits
+		}
+	}
+	return filen.bandwidthLimits
+}
+
+// newThrottles creates the throttles the client applies to chunk transfers.
+func (filen *Filen) newThrottles() (upload *client.Throttle, download *client.Throttle) {
+	upload = client.NewThrottle(func(now time.Time) client.Bandwidth {
+		limits := filen.bandwidthLimitsAt(now)
+		return client.Bandwidth{Rate: limits.Upload, Burst: limits.UploadBurst}
+	})
+	download = client.NewThrottle(func(now time.Time) client.Bandwidth {
+		limits := filen.bandwidthLimitsAt(now)
+		return client.Bandwidth{Rate: limits.Download, Burst: limits.DownloadBurst}
+	})
+	return upload, download
+}
@@ -0,0 +1,82 @@
+package filen
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBandwidthRuleMatches(t *testing.T) {
+	businessHours := BandwidthRule{
+		Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+		Start:    9 * time.Hour,
+		End:      17 * time.Hour,
+	}
+	fridayNight := BandwidthRule{
+		Weekdays: []time.Weekday{time.Friday},
+		Start:    22 * time.Hour,
+		End:      6 * time.Hour,
+	}
+	everyNight := BandwidthRule{Start: 22 * time.Hour, End: 6 * time.Hour}
+
+	// 2024-01-01 is a Monday
+	at := func(day int, hour int, minute int, second int) time.Time {
+		return time.Date(2024, time.January, day, hour, minute, second, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		rule BandwidthRule
+		now  time.Time
+		want bool
+	}{
+		{"before start", businessHours, at(1, 8, 59, 59), false},
+		{"at start", businessHours, at(1, 9, 0, 0), true},
+		{"before end", businessHours, at(1, 16, 59, 59), true},
+		{"at end", businessHours, at(1, 17, 0, 0), false},
+		{"other weekday", businessHours, at(6, 12, 0, 0), false},
+		{"empty window", BandwidthRule{Start: time.Hour, End: time.Hour}, at(1, 1, 0, 0), false},
+		{"whole day", BandwidthRule{End: 24 * time.Hour}, at(1, 23, 59, 59), true},
+
+		{"wrapping, before start", fridayNight, at(5, 21, 59, 59), false},
+		{"wrapping, at start", fridayNight, at(5, 22, 0, 0), true},
+		{"wrapping, at midnight", fridayNight, at(6, 0, 0, 0), true},
+		{"wrapping, before end on the next day", fridayNight, at(6, 5, 59, 59), true},
+		{"wrapping, at end on the next day", fridayNight, at(6, 6, 0, 0), false},
+		{"wrapping, started on another weekday", fridayNight, at(5, 5, 0, 0), false},
+		{"wrapping, evening of the next day", fridayNight, at(6, 23, 0, 0), false},
+		{"wrapping every day, morning", everyNight, at(1, 5, 0, 0), true},
+		{"wrapping every day, evening", everyNight, at(1, 23, 0, 0), true},
+		{"wrapping every day, noon", everyNight, at(1, 12, 0, 0), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.rule.matches(test.now); got != test.want {
+				t.Errorf("matches(%v) = %v, want %v", test.now, got, test.want)
+			}
+		})
+	}
+}
+
+func TestBandwidthLimitsAt(t *testing.T) {
+	filen := &Filen{}
+	filen.SetBandwidthLimits(BandwidthLimits{Upload: 1})
+	filen.SetBandwidthSchedule([]BandwidthRule{
+		{Start: 9 * time.Hour, End: 17 * time.Hour, Limits: BandwidthLimits{Upload: 2}},
+		{Start: 12 * time.Hour, End: 13 * time.Hour, Limits: BandwidthLimits{Upload: 3}},
+	})
+
+	tests := []struct {
+		hour int
+		want int64
+	}{
+		{8, 1},  // no rule matches
+		{10, 2}, // the first rule matches
+		{12, 2}, // both rules match, the first one wins
+		{17, 1},
+	}
+	for _, test := range tests {
+		now := time.Date(2024, time.January, 1, test.hour, 0, 0, 0, time.UTC)
+		if got := filen.bandwidthLimitsAt(now).Upload; got != test.want {
+			t.Errorf("upload limit at %d:00 = %d, want %d", test.hour, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,297 @@
+package filen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCacheMaxEntries = 4096
+
+// CacheOptions configures the metadata cache, which keeps decrypted directory listings
+// and the UUIDs of directories by path, so that resolving paths doesn't need to list every parent directory again.
+//
+// The cache is invalidated automatically when items are changed through this Filen instance,
+// but not when they are changed elsewhere (e.g. by another client); use the TTL to bound how stale results can be,
+// or invalidate the cache explicitly using [Filen.InvalidateCache] and [Filen.InvalidateCachedDirectory].
+//
+// If a Directory is set, the cache is also persisted there, so that it survives restarts and can be shared
+// by consecutive processes (e.g. CLI invocations). Entries are stored encrypted with a key derived from the master key.
+// The directory should be dedicated to the cache of one account. Persisted entries are not refreshed
+// before they expire, so a long TTL makes them most useful; expired entries are refreshed lazily when they are next needed.
+// Entries that cannot be persisted are still cached in memory, and the error is reported to OnError.
+type CacheOptions struct {
+	TTL        time.Duration   // how long cached entries are used; the cache is disabled if this is zero (the default)
+	MaxEntries int             // the maximum number of cached listings and of cached paths (0 for a default)
+	Directory  string          // a local directory to persist the cache in, or empty to keep it in memory only
+	OnError    func(err error) // called when an entry cannot be persisted (nil to ignore such errors)
+}
+
+// SetCacheOptions enables, configures or disables the metadata cache (see [CacheOptions]).
+// This clears the in-memory cache and loads the persisted entries, if any.
+func (filen *Filen) SetCacheOptions(options CacheOptions) error {
+	if options.MaxEntries <= 0 {
+		options.MaxEntries = defaultCacheMaxEntries
+	}
+	var store *cacheStore
+	var storedEntries []*storedEntry
+	if options.TTL > 0 && options.Directory != "" {
+		var err error
+		store, err = newCacheStore(options.Directory, filen.CurrentMasterKey())
+		if err != nil {
+			return err
+		}
+		storedEntries, err = store.load()
+		if err != nil {
+			return err
+		}
+	}
+
+	filen.cache.mu.Lock()
+	defer filen.cache.mu.Unlock()
+	filen.cache.options = options
+	filen.cache.store = store
+	filen.cache.clear()
+	for _, entry := range storedEntries {
+		switch entry.Kind {
+		case cacheKindListing:
+			filen.cache.insertListing(entry.Key, &cachedListing{entry.Files, entry.Directories, entry.Expires})
+		case cacheKindPath:
+			filen.cache.insertPath(entry.Key, &cachedDirectory{entry.UUID, entry.Expires})
+		}
+	}
+	return nil
+}
+
+// CacheOptions returns the options of the metadata cache.
+func (filen *Filen) CacheOptions() CacheOptions {
+	filen.cache.mu.Lock()
+	defer filen.cache.mu.Unlock()
+	return filen.cache.options
+}
+
+// InvalidateCache removes all entries from the metadata cache.
+func (filen *Filen) InvalidateCache() {
+	filen.cache.invalidateAll()
+}
+
+// InvalidateCachedDirectory removes the listing of a directory (specified by UUID) from the metadata cache,
+// as well as the paths of the directory and its subdirectories.
+func (filen *Filen) InvalidateCachedDirectory(uuid string) {
+	filen.cache.invalidateItem(uuid)
+}
+
+// metadataCache holds the cached listings and paths. Its zero value is a disabled cache.
+type metadataCache struct {
+	mu       sync.Mutex
+	options  CacheOptions
+	listings map[string]*cachedListing   // by directory UUID
+	paths    map[string]*cachedDirectory // by cleaned path (see cachePath), including "/" for the base folder
+	store    *cacheStore                 // persists the entries, if enabled
+}
+
+type cachedListing struct {
+	files       []*File
+	directories []*Directory
+	expires     time.Time
+}
+
+type cachedDirectory struct {
+	uuid    string
+	expires time.Time
+}
+
+// cachePath returns the key under which the directory at the path given by segments is cached.
+// Paths resolved with different name matching modes are cached separately.
+func cachePath(matching NameMatching, segments []string) string {
+	keys := make([]string, len(segments))
+	for i, segment := range segments {
+		keys[i] = matching.key(segment)
+	}
+	return matching.cachePrefix() + "/" + strings.Join(keys, "/")
+}
+
+func (cache *metadataCache) enabled() bool {
+	return cache.options.TTL > 0
+}
+
+// active is like enabled, for use without holding the lock.
+func (cache *metadataCache) active() bool {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	return cache.enabled()
+}
+
+func (cache *metadataCache) clear() {
+	cache.listings = make(map[string]*cachedListing)
+	cache.paths = make(map[string]*cachedDirectory)
+}
+
+// listing returns the cached listing of a directory, if there is one.
+// The slices are copies, but the items are shared and must not be modified.
+func (cache *metadataCache) listing(uuid string) ([]*File, []*Directory, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	listing, ok := cache.listings[uuid]
+	if !ok || time.Now().After(listing.expires) {
+		return nil, nil, false
+	}
+	return slices.Clone(listing.files), slices.Clone(listing.directories), true
+}
+
+func (cache *metadataCache) putListing(uuid string, files []*File, directories []*Directory) {
+	cache.mu.Lock()
+	if !cache.enabled() {
+		cache.mu.Unlock()
+		return
+	}
+	listing := &cachedListing{slices.Clone(files), slices.Clone(directories), time.Now().Add(cache.options.TTL)}
+	cache.insertListing(uuid, listing)
+	store := cache.store
+	cache.mu.Unlock()
+
+	if store != nil {
+		entry := &storedEntry{Kind: cacheKindListing, Key: uuid, Files: listing.files, Directories: listing.directories, Expires: listing.expires}
+		cache.persist(store, entry, func() bool { return cache.listings[uuid] == listing })
+	}
+}
+
+func (cache *metadataCache) insertListing(uuid string, listing *cachedListing) {
+	makeRoom(cache.listings, cache.options.MaxEntries, func(listing *cachedListing) time.Time { return listing.expires },
+		func(uuid string) { cache.removeStored(cacheKindListing, uuid) })
+	cache.listings[uuid] = listing
+}
+
+// directoryUUID returns the cached UUID of the directory at a path, if there is one.
+func (cache *metadataCache) directoryUUID(path string) (string, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	directory, ok := cache.paths[path]
+	if !ok || time.Now().After(directory.expires) {
+		return "", false
+	}
+	return directory.uuid, true
+}
+
+func (cache *metadataCache) putDirectoryUUID(path string, uuid string) {
+	cache.mu.Lock()
+	if !cache.enabled() {
+		cache.mu.Unlock()
+		return
+	}
+	directory := &cachedDirectory{uuid, time.Now().Add(cache.options.TTL)}
+	cache.insertPath(path, directory)
+	store := cache.store
+	cache.mu.Unlock()
+
+	if store != nil {
+		entry := &storedEntry{Kind: cacheKindPath, Key: path, UUID: uuid, Expires: directory.expires}
+		cache.persist(store, entry, func() bool { return cache.paths[path] == directory })
+	}
+}
+
+func (cache *metadataCache) insertPath(path string, directory *cachedDirectory) {
+	makeRoom(cache.paths, cache.options.MaxEntries, func(directory *cachedDirectory) time.Time { return directory.expires },
+		func(path string) { cache.removeStored(cacheKindPath, path) })
+	cache.paths[path] = directory
+}
+
+// persist saves an entry that has been inserted, without holding the lock so that lookups don't wait for disk I/O.
+// If the entry has been invalidated or replaced in the meantime (current, called with the lock held, returns false),
+// the saved file is removed again, so that it doesn't outlive the invalidation. Errors are reported to OnError.
+func (cache *metadataCache) persist(store *cacheStore, entry *storedEntry, current func() bool) {
+	err := store.save(entry)
+
+	cache.mu.Lock()
+	stale := cache.store != store || !current()
+	onError := cache.options.OnError
+	cache.mu.Unlock()
+
+	if err != nil {
+		if onError != nil {
+			onError(fmt.Errorf("persist metadata cache entry: %w", err))
+		}
+		return
+	}
+	if stale {
+		store.remove(entry.Kind, entry.Key)
+	}
+}
+
+// removeStored removes a persisted entry, if the cache is persisted.
+func (cache *metadataCache) removeStored(kind string, key string) {
+	if cache.store != nil {
+		cache.store.remove(kind, key)
+	}
+}
+
+// makeRoom removes expired entries from a full cache map, and if that isn't enough, the entry that expires first.
+// The key of every removed entry is passed to removed.
+func makeRoom[T any](entries map[string]T, maxEntries int, expires func(entry T) time.Time, removed func(key string)) {
+	if len(entries) < maxEntries {
+		return
+	}
+	now := time.Now()
+	var firstKey string
+	var first time.Time
+	for key, entry := range entries {
+		entryExpires := expires(entry)
+		if now.After(entryExpires) {
+			delete(entries, key)
+			removed(key)
+		} else if firstKey == "" || entryExpires.Before(first) {
+			firstKey, first = key, entryExpires
+		}
+	}
+	if len(entries) >= maxEntries {
+		delete(entries, firstKey)
+		removed(firstKey)
+	}
+}
+
+func (cache *metadataCache) invalidateAll() {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.clear()
+	if cache.store != nil {
+		cache.store.removeAll()
+	}
+}
+
+// invalidateDirectory removes the listing of a directory, e.g. after an item has been added to it.
+func (cache *metadataCache) invalidateDirectory(uuid string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.listings, uuid)
+	cache.removeStored(cacheKindListing, uuid)
+}
+
+// invalidateItem removes everything that involves an item that has been changed:
+// its own listing, the listings that contain it, and its path and the paths below it.
+func (cache *metadataCache) invalidateItem(uuid string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	delete(cache.listings, uuid)
+	cache.removeStored(cacheKindListing, uuid)
+	for directoryUUID, listing := range cache.listings {
+		if slices.ContainsFunc(listing.files, func(file *File) bool { return file.UUID == uuid }) ||
+			slices.ContainsFunc(listing.directories, func(directory *Directory) bool { return directory.UUID == uuid }) {
+			delete(cache.listings, directoryUUID)
+			cache.removeStored(cacheKindListing, directoryUUID)
+		}
+	}
+	for path, directory := range cache.paths {
+		if directory.uuid != uuid {
+			continue
+		}
+		prefix := strings.TrimSuffix(path, "/") + "/"
+		for otherPath := range cache.paths {
+			if otherPath == path || strings.HasPrefix(otherPath, prefix) {
+				delete(cache.paths, otherPath)
+				cache.removeStored(cacheKindPath, otherPath)
+			}
+		}
+	}
+}
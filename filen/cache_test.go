@@ -0,0 +1,206 @@
+package filen
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestCache returns a Filen with an enabled in-memory metadata cache containing the listings and paths of
+//
+//	/       (root)
+//	/a      (a), containing the file f
+//	/a/b    (b)
+//	/ab     (ab)
+func newTestCache(t *testing.T) *Filen {
+	filen := &Filen{}
+	err := filen.SetCacheOptions(CacheOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	filen.cache.putListing("root", nil, []*Directory{{UUID: "a", Name: "a"}, {UUID: "ab", Name: "ab"}})
+	filen.cache.putListing("a", []*File{{UUID: "f", Name: "f"}}, []*Directory{{UUID: "b", Name: "b"}})
+	filen.cache.putListing("b", nil, nil)
+	filen.cache.putListing("ab", nil, nil)
+	filen.cache.putDirectoryUUID("/", "root")
+	filen.cache.putDirectoryUUID("/a", "a")
+	filen.cache.putDirectoryUUID("/a/b", "b")
+	filen.cache.putDirectoryUUID("/ab", "ab")
+	return filen
+}
+
+// cachedKeys returns the UUIDs of the cached listings and the cached paths, sorted.
+func cachedKeys(cache *metadataCache) (listings []string, paths []string) {
+	for uuid := range cache.listings {
+		if _, _, ok := cache.listing(uuid); ok {
+			listings = append(listings, uuid)
+		}
+	}
+	for path := range cache.paths {
+		if _, ok := cache.directoryUUID(path); ok {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(listings)
+	slices.Sort(paths)
+	return listings, paths
+}
+
+func TestMetadataCacheDisabled(t *testing.T) {
+	cache := &metadataCache{}
+	cache.putListing("root", []*File{{UUID: "f"}}, nil)
+	cache.putDirectoryUUID("/", "root")
+	if _, _, ok := cache.listing("root"); ok {
+		t.Error("disabled cache returned a listing")
+	}
+	if _, ok := cache.directoryUUID("/"); ok {
+		t.Error("disabled cache returned a path")
+	}
+}
+
+func TestMetadataCacheTTL(t *testing.T) {
+	filen := newTestCache(t)
+	files, directories, ok := filen.cache.listing("a")
+	if !ok || len(files) != 1 || len(directories) != 1 {
+		t.Fatalf("listing = %v, %v, %v", files, directories, ok)
+	}
+	if uuid, ok := filen.cache.directoryUUID("/a/b"); !ok || uuid != "b" {
+		t.Fatalf("directoryUUID = %q, %v", uuid, ok)
+	}
+
+	expired := time.Now().Add(-time.Second)
+	filen.cache.listings["a"].expires = expired
+	filen.cache.paths["/a/b"].expires = expired
+	if _, _, ok := filen.cache.listing("a"); ok {
+		t.Error("expired listing returned")
+	}
+	if _, ok := filen.cache.directoryUUID("/a/b"); ok {
+		t.Error("expired path returned")
+	}
+	if _, ok := filen.cache.directoryUUID("/a"); !ok {
+		t.Error("unexpired path not returned")
+	}
+}
+
+func TestMetadataCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		invalidate   func(filen *Filen)
+		wantListings []string
+		wantPaths    []string
+	}{
+		{
+			"directory listing",
+			func(filen *Filen) { filen.cache.invalidateDirectory("a") },
+			[]string{"ab", "b", "root"},
+			[]string{"/", "/a", "/a/b", "/ab"},
+		},
+		{
+			"file",
+			func(filen *Filen) { filen.cache.invalidateItem("f") },
+			[]string{"ab", "b", "root"},
+			[]string{"/", "/a", "/a/b", "/ab"},
+		},
+		{
+			// the listing of a, the listing containing a, and the paths of a and below, but not of /ab
+			"directory",
+			func(filen *Filen) { filen.InvalidateCachedDirectory("a") },
+			[]string{"ab", "b"},
+			[]string{"/", "/ab"},
+		},
+		{
+			"root",
+			func(filen *Filen) { filen.InvalidateCachedDirectory("root") },
+			[]string{"a", "ab", "b"},
+			nil,
+		},
+		{
+			"everything",
+			func(filen *Filen) { filen.InvalidateCache() },
+			nil,
+			nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filen := newTestCache(t)
+			test.invalidate(filen)
+			listings, paths := cachedKeys(&filen.cache)
+			if !slices.Equal(listings, test.wantListings) {
+				t.Errorf("listings = %v, want %v", listings, test.wantListings)
+			}
+			if !slices.Equal(paths, test.wantPaths) {
+				t.Errorf("paths = %v, want %v", paths, test.wantPaths)
+			}
+		})
+	}
+}
+
+func TestMakeRoom(t *testing.T) {
+	now := time.Now()
+	entries := func() map[string]time.Time {
+		return map[string]time.Time{
+			"expired": now.Add(-time.Minute),
+			"first":   now.Add(time.Minute),
+			"second":  now.Add(2 * time.Minute),
+		}
+	}
+	tests := []struct {
+		name        string
+		maxEntries  int
+		wantRemoved []string
+	}{
+		{"not full", 4, nil},
+		{"expired entries suffice", 3, []string{"expired"}},
+		{"evict the entry that expires first", 2, []string{"expired", "first"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entries := entries()
+			var removed []string
+			makeRoom(entries, test.maxEntries, func(expires time.Time) time.Time { return expires },
+				func(key string) { removed = append(removed, key) })
+			slices.Sort(removed)
+			if !slices.Equal(removed, test.wantRemoved) {
+				t.Errorf("removed %v, want %v", removed, test.wantRemoved)
+			}
+			if len(entries) != 3-len(test.wantRemoved) {
+				t.Errorf("%d entries left", len(entries))
+			}
+		})
+	}
+}
+
+func TestMetadataCacheMaxEntries(t *testing.T) {
+	filen := &Filen{}
+	err := filen.SetCacheOptions(CacheOptions{TTL: time.Hour, MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, uuid := range []string{"first", "second", "third"} {
+		filen.cache.putListing(uuid, nil, nil)
+		time.Sleep(time.Millisecond) // so that the entries expire in order
+	}
+	listings, _ := cachedKeys(&filen.cache)
+	if want := []string{"second", "third"}; !slices.Equal(listings, want) {
+		t.Errorf("listings = %v, want %v", listings, want)
+	}
+}
+
+func TestCachePath(t *testing.T) {
+	tests := []struct {
+		matching NameMatching
+		segments []string
+		want     string
+	}{
+		{NameMatchingExact, nil, "/"},
+		{NameMatchingExact, []string{"A", "Straße"}, "/A/Straße"},
+		{NameMatchingNormalized, []string{"Cafe\u0301"}, "nfc:/Caf\u00e9"},
+		{NameMatchingCaseInsensitive, []string{"A", "Straße"}, "fold:/a/strasse"},
+	}
+	for _, test := range tests {
+		if got := cachePath(test.matching, test.segments); got != test.want {
+			t.Errorf("cachePath(%v, %q) = %q, want %q", test.matching, test.segments, got, test.want)
+		}
+	}
+}
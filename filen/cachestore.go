@@ -0,0 +1,143 @@
+package filen
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/crypto"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// cacheStoreSalt is used to derive the key cache files are encrypted with from the master key.
+const cacheStoreSalt = "filen-sdk-go metadata cache"
+
+// cacheStore persists the entries of the metadata cache in a local directory.
+// Every entry is stored in its own file, encrypted with a key derived from the master key
+// and named by a keyed hash, so that the files disclose neither names nor UUIDs.
+type cacheStore struct {
+	directory string
+	key       []byte
+}
+
+// storedEntry is the content of a cache file, either a listing or a path.
+type storedEntry struct {
+	Kind        string       `json:"kind"` // cacheKindListing or cacheKindPath
+	Key         string       `json:"key"`  // the directory UUID of a listing, or the path
+	Files       []*File      `json:"files,omitempty"`
+	Directories []*Directory `json:"directories,omitempty"`
+	UUID        string       `json:"uuid,omitempty"` // the directory UUID of a path
+	Expires     time.Time    `json:"expires"`
+}
+
+const (
+	cacheKindListing = "listing"
+	cacheKindPath    = "path"
+)
+
+func newCacheStore(directory string, masterKey []byte) (*cacheStore, error) {
+	err := os.MkdirAll(directory, 0700)
+	if err != nil {
+		return nil, err
+	}
+	return &cacheStore{directory, crypto.DeriveKeyFromPassword(string(masterKey), cacheStoreSalt, 1, 256)}, nil
+}
+
+// fileName returns the name of the file an entry is stored in.
+func (store *cacheStore) fileName(kind string, key string) string {
+	mac := hmac.New(sha256.New, store.key)
+	mac.Write([]byte(kind + ":" + key))
+	return filepath.Join(store.directory, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// isCacheFile reports whether a directory entry is a cache file (rather than e.g. a temporary file).
+func isCacheFile(entry fs.DirEntry) bool {
+	_, err := hex.DecodeString(entry.Name())
+	return entry.Type().IsRegular() && len(entry.Name()) == 2*sha256.Size && err == nil
+}
+
+// save writes an entry, replacing the file atomically so that concurrent processes never read partial entries.
+func (store *cacheStore) save(entry *storedEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data, err = crypto.EncryptData(data, store.key)
+	if err != nil {
+		return err
+	}
+	fileName := store.fileName(entry.Kind, entry.Key)
+	temp, err := os.CreateTemp(store.directory, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = temp.Write(data)
+	err = errors.Join(err, temp.Close())
+	if err == nil {
+		err = os.Rename(temp.Name(), fileName)
+	}
+	if err != nil {
+		_ = os.Remove(temp.Name())
+	}
+	return err
+}
+
+// remove deletes the file of an entry, if there is one.
+func (store *cacheStore) remove(kind string, key string) {
+	_ = os.Remove(store.fileName(kind, key))
+}
+
+// removeAll deletes all cache files.
+func (store *cacheStore) removeAll() {
+	entries, _ := os.ReadDir(store.directory)
+	for _, entry := range entries {
+		if isCacheFile(entry) {
+			_ = os.Remove(filepath.Join(store.directory, entry.Name()))
+		}
+	}
+}
+
+// load reads all entries that haven't expired yet. Expired entries, and entries that cannot be decrypted
+// (e.g. because they were written with a previous master key), are deleted.
+func (store *cacheStore) load() ([]*storedEntry, error) {
+	dirEntries, err := os.ReadDir(store.directory)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*storedEntry, 0)
+	now := time.Now()
+	for _, dirEntry := range dirEntries {
+		if !isCacheFile(dirEntry) {
+			continue
+		}
+		fileName := filepath.Join(store.directory, dirEntry.Name())
+		entry, err := store.read(fileName)
+		if err != nil || now.After(entry.Expires) || store.fileName(entry.Kind, entry.Key) != fileName {
+			_ = os.Remove(fileName)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (store *cacheStore) read(fileName string) (*storedEntry, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	data, err = crypto.DecryptData(data, store.key)
+	if err != nil {
+		return nil, err
+	}
+	entry := &storedEntry{}
+	err = json.Unmarshal(data, entry)
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,142 @@
+package filen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheStore(t *testing.T) {
+	directory := t.TempDir()
+	masterKey := []byte("0123456789abcdef0123456789abcdef")
+	options := CacheOptions{TTL: time.Hour, Directory: directory}
+
+	filen := &Filen{MasterKeys: [][]byte{masterKey}}
+	err := filen.SetCacheOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filen.cache.putListing("directory-uuid", []*File{{UUID: "f", Name: "secret.txt", EncryptionKey: []byte("key")}}, nil)
+	filen.cache.putListing("other-uuid", nil, nil)
+	filen.cache.putDirectoryUUID("/secret", "directory-uuid")
+
+	// the files disclose neither names nor UUIDs
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("%d cache files, want 3", len(entries))
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(directory, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, plaintext := range []string{"secret", "directory-uuid"} {
+			if strings.Contains(entry.Name(), plaintext) || strings.Contains(string(data), plaintext) {
+				t.Errorf("cache file %s contains %q", entry.Name(), plaintext)
+			}
+		}
+	}
+
+	// another instance loads the entries, except for invalidated ones
+	filen.cache.invalidateDirectory("other-uuid")
+	loaded := &Filen{MasterKeys: [][]byte{masterKey}}
+	err = loaded.SetCacheOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, _, ok := loaded.cache.listing("directory-uuid")
+	if !ok || len(files) != 1 || files[0].Name != "secret.txt" || string(files[0].EncryptionKey) != "key" {
+		t.Errorf("loaded listing = %v, %v", files, ok)
+	}
+	if uuid, ok := loaded.cache.directoryUUID("/secret"); !ok || uuid != "directory-uuid" {
+		t.Errorf("loaded path = %q, %v", uuid, ok)
+	}
+	if _, _, ok := loaded.cache.listing("other-uuid"); ok {
+		t.Error("invalidated listing loaded")
+	}
+
+	// entries written with another master key are discarded
+	otherKey := &Filen{MasterKeys: [][]byte{[]byte("another master key")}}
+	err = otherKey.SetCacheOptions(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listings, paths := cachedKeys(&otherKey.cache); len(listings)+len(paths) != 0 {
+		t.Errorf("loaded %v and %v with another master key", listings, paths)
+	}
+	entries, _ = os.ReadDir(directory)
+	if len(entries) != 0 {
+		t.Errorf("%d undecryptable cache files left", len(entries))
+	}
+}
+
+func TestCacheStoreExpired(t *testing.T) {
+	directory := t.TempDir()
+	filen := &Filen{MasterKeys: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}}
+	err := filen.SetCacheOptions(CacheOptions{TTL: time.Hour, Directory: directory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = filen.cache.store.save(&storedEntry{Kind: cacheKindPath, Key: "/old", UUID: "old", Expires: time.Now().Add(-time.Second)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = filen.SetCacheOptions(CacheOptions{TTL: time.Hour, Directory: directory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := filen.cache.directoryUUID("/old"); ok {
+		t.Error("expired entry loaded")
+	}
+	entries, _ := os.ReadDir(directory)
+	if len(entries) != 0 {
+		t.Errorf("%d expired cache files left", len(entries))
+	}
+}
+
+func TestCacheStoreErrors(t *testing.T) {
+	directory := filepath.Join(t.TempDir(), "cache")
+	filen := &Filen{MasterKeys: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}}
+	var errs []error
+	err := filen.SetCacheOptions(CacheOptions{TTL: time.Hour, Directory: directory, OnError: func(err error) { errs = append(errs, err) }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.RemoveAll(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// entries that cannot be persisted are still cached in memory
+	filen.cache.putListing("directory-uuid", nil, nil)
+	filen.cache.putDirectoryUUID("/directory", "directory-uuid")
+	if len(errs) != 2 {
+		t.Errorf("%d errors reported, want 2: %v", len(errs), errs)
+	}
+	if _, _, ok := filen.cache.listing("directory-uuid"); !ok {
+		t.Error("listing not cached")
+	}
+	if _, ok := filen.cache.directoryUUID("/directory"); !ok {
+		t.Error("path not cached")
+	}
+}
+
+func TestCacheStoreInvalidatedWhileSaving(t *testing.T) {
+	directory := t.TempDir()
+	filen := &Filen{MasterKeys: [][]byte{[]byte("0123456789abcdef0123456789abcdef")}}
+	err := filen.SetCacheOptions(CacheOptions{TTL: time.Hour, Directory: directory})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &storedEntry{Kind: cacheKindPath, Key: "/directory", UUID: "directory-uuid", Expires: time.Now().Add(time.Hour)}
+	filen.cache.persist(filen.cache.store, entry, func() bool { return false })
+	entries, _ := os.ReadDir(directory)
+	if len(entries) != 0 {
+		t.Errorf("%d cache files left for an invalidated entry", len(entries))
+	}
+}
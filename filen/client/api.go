@@ -57,29 +57,33 @@ func (client *Client) GetUserBaseFolder() (*UserBaseFolder, error) {
 // /v3/dir/content
 
 type DirectoryContent struct {
-	Uploads []struct {
-		UUID      string                 `json:"uuid"`
-		Metadata  crypto.EncryptedString `json:"metadata"`
-		Rm        string                 `json:"rm"`
-		Timestamp int                    `json:"timestamp"`
-		Chunks    int                    `json:"chunks"`
-		Size      int                    `json:"size"`
-		Bucket    string                 `json:"bucket"`
-		Region    string                 `json:"region"`
-		Parent    string                 `json:"parent"`
-		Version   int                    `json:"version"`
-		Favorited int                    `json:"favorited"`
-	} `json:"uploads"`
-	Folders []struct {
-		UUID      string                 `json:"uuid"`
-		Name      crypto.EncryptedString `json:"name"`
-		Parent    string                 `json:"parent"`
-		Color     interface{}            `json:"color"`
-		Timestamp int                    `json:"timestamp"`
-		Favorited int                    `json:"favorited"`
-		IsSync    int                    `json:"is_sync"`
-		IsDefault int                    `json:"is_default"`
-	} `json:"folders"`
+	Uploads []DirectoryContentUpload `json:"uploads"`
+	Folders []DirectoryContentFolder `json:"folders"`
+}
+
+type DirectoryContentUpload struct {
+	UUID      string                 `json:"uuid"`
+	Metadata  crypto.EncryptedString `json:"metadata"`
+	Rm        string                 `json:"rm"`
+	Timestamp int                    `json:"timestamp"`
+	Chunks    int                    `json:"chunks"`
+	Size      int                    `json:"size"`
+	Bucket    string                 `json:"bucket"`
+	Region    string                 `json:"region"`
+	Parent    string                 `json:"parent"`
+	Version   int                    `json:"version"`
+	Favorited int                    `json:"favorited"`
+}
+
+type DirectoryContentFolder struct {
+	UUID      string                 `json:"uuid"`
+	Name      crypto.EncryptedString `json:"name"`
+	Parent    string                 `json:"parent"`
+	Color     interface{}            `json:"color"`
+	Timestamp int                    `json:"timestamp"`
+	Favorited int                    `json:"favorited"`
+	IsSync    int                    `json:"is_sync"`
+	IsDefault int                    `json:"is_default"`
 }
 
 // GetDirectoryContent calls /v3/dir/content.
@@ -152,6 +156,20 @@ func (client *Client) TrashFile(uuid string) error {
 	return nil
 }
 
+// /v3/file/delete/permanent
+
+// DeleteFilePermanently calls /v3/file/delete/permanent
+func (client *Client) DeleteFilePermanently(uuid string) error {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	_, err := client.Request("POST", "/v3/file/delete/permanent", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 // /v3/dir/create
 
 type CreateDirectoryResponse struct {
@@ -187,3 +205,326 @@ func (client *Client) TrashDirectory(uuid string) error {
 	}
 	return nil
 }
+
+// /v3/file/rename
+
+// RenameFile calls /v3/file/rename
+func (client *Client) RenameFile(uuid string, name crypto.EncryptedString, nameHashed string, metadata crypto.EncryptedString) error {
+	request := struct {
+		UUID       string                 `json:"uuid"`
+		Name       crypto.EncryptedString `json:"name"`
+		NameHashed string                 `json:"nameHashed"`
+		Metadata   crypto.EncryptedString `json:"metadata"`
+	}{uuid, name, nameHashed, metadata}
+	_, err := client.Request("POST", "/v3/file/rename", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/dir/rename
+
+// RenameDirectory calls /v3/dir/rename
+func (client *Client) RenameDirectory(uuid string, name crypto.EncryptedString, nameHashed string) error {
+	request := struct {
+		UUID       string                 `json:"uuid"`
+		Name       crypto.EncryptedString `json:"name"`
+		NameHashed string                 `json:"nameHashed"`
+	}{uuid, name, nameHashed}
+	_, err := client.Request("POST", "/v3/dir/rename", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/file/move
+
+// MoveFile calls /v3/file/move
+func (client *Client) MoveFile(uuid string, parentUUID string) error {
+	request := struct {
+		UUID       string `json:"uuid"`
+		ParentUUID string `json:"to"`
+	}{uuid, parentUUID}
+	_, err := client.Request("POST", "/v3/file/move", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/dir/move
+
+// MoveDirectory calls /v3/dir/move
+func (client *Client) MoveDirectory(uuid string, parentUUID string) error {
+	request := struct {
+		UUID       string `json:"uuid"`
+		ParentUUID string `json:"to"`
+	}{uuid, parentUUID}
+	_, err := client.Request("POST", "/v3/dir/move", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/dir
+
+type DirectoryInfo struct {
+	UUID          string                 `json:"uuid"`
+	NameEncrypted crypto.EncryptedString `json:"nameEncrypted"`
+	Parent        string                 `json:"parent"`
+	Trash         bool                   `json:"trash"`
+	Favorited     bool                   `json:"favorited"`
+	Color         interface{}            `json:"color"`
+}
+
+// GetDirectory calls /v3/dir
+func (client *Client) GetDirectory(uuid string) (*DirectoryInfo, error) {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	response := &DirectoryInfo{}
+	_, err := client.Request("POST", "/v3/dir", request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// /v3/file/restore
+
+// RestoreFile calls /v3/file/restore
+func (client *Client) RestoreFile(uuid string) error {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	_, err := client.Request("POST", "/v3/file/restore", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/dir/restore
+
+// RestoreDirectory calls /v3/dir/restore
+func (client *Client) RestoreDirectory(uuid string) error {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	_, err := client.Request("POST", "/v3/dir/restore", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/dir/delete/permanent
+
+// DeleteDirectoryPermanently calls /v3/dir/delete/permanent
+func (client *Client) DeleteDirectoryPermanently(uuid string) error {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	_, err := client.Request("POST", "/v3/dir/delete/permanent", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/trash/empty
+
+// EmptyTrash calls /v3/trash/empty
+func (client *Client) EmptyTrash() error {
+	_, err := client.Request("POST", "/v3/trash/empty", nil, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/file/versions
+
+type FileVersions struct {
+	Versions []struct {
+		UUID      string                 `json:"uuid"`
+		Bucket    string                 `json:"bucket"`
+		Region    string                 `json:"region"`
+		Chunks    int                    `json:"chunks"`
+		Metadata  crypto.EncryptedString `json:"metadata"`
+		Rm        string                 `json:"rm"`
+		Timestamp int                    `json:"timestamp"`
+		Version   int                    `json:"version"`
+	} `json:"versions"`
+}
+
+// GetFileVersions calls /v3/file/versions
+func (client *Client) GetFileVersions(uuid string) (*FileVersions, error) {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	response := &FileVersions{}
+	_, err := client.Request("POST", "/v3/file/versions", request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// /v3/file/version/restore
+
+// RestoreFileVersion calls /v3/file/version/restore
+func (client *Client) RestoreFileVersion(uuid string, currentUUID string) error {
+	request := struct {
+		UUID        string `json:"uuid"`
+		CurrentUUID string `json:"current"`
+	}{uuid, currentUUID}
+	_, err := client.Request("POST", "/v3/file/version/restore", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/dir/color
+
+// SetDirectoryColor calls /v3/dir/color
+func (client *Client) SetDirectoryColor(uuid string, color string) error {
+	request := struct {
+		UUID  string `json:"uuid"`
+		Color string `json:"color"`
+	}{uuid, color}
+	_, err := client.Request("POST", "/v3/dir/color", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/item/favorite
+
+// SetFavorite calls /v3/item/favorite (itemType is either "file" or "folder")
+func (client *Client) SetFavorite(uuid string, itemType string, favorite bool) error {
+	value := 0
+	if favorite {
+		value = 1
+	}
+	request := struct {
+		UUID  string `json:"uuid"`
+		Type  string `json:"type"`
+		Value int    `json:"value"`
+	}{uuid, itemType, value}
+	_, err := client.Request("POST", "/v3/item/favorite", request, nil)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// /v3/file
+
+type FileInfo struct {
+	UUID          string                 `json:"uuid"`
+	Region        string                 `json:"region"`
+	Bucket        string                 `json:"bucket"`
+	NameEncrypted crypto.EncryptedString `json:"nameEncrypted"`
+	NameHashed    string                 `json:"nameHashed"`
+	SizeEncrypted crypto.EncryptedString `json:"sizeEncrypted"`
+	MimeEncrypted crypto.EncryptedString `json:"mimeEncrypted"`
+	Metadata      crypto.EncryptedString `json:"metadata"`
+	Size          int                    `json:"size"`
+	Parent        string                 `json:"parent"`
+	Versioned     bool                   `json:"versioned"`
+	Trash         bool                   `json:"trash"`
+	Version       int                    `json:"version"`
+}
+
+// GetFile calls /v3/file
+func (client *Client) GetFile(uuid string) (*FileInfo, error) {
+	request := struct {
+		UUID string `json:"uuid"`
+	}{uuid}
+	response := &FileInfo{}
+	_, err := client.Request("POST", "/v3/file", request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// /v3/file/exists, /v3/dir/exists
+
+type ItemExists struct {
+	Exists bool   `json:"exists"`
+	UUID   string `json:"uuid"`
+}
+
+// FileExists calls /v3/file/exists
+func (client *Client) FileExists(parentUUID string, nameHashed string) (*ItemExists, error) {
+	request := struct {
+		ParentUUID string `json:"parent"`
+		NameHashed string `json:"nameHashed"`
+	}{parentUUID, nameHashed}
+	response := &ItemExists{}
+	_, err := client.Request("POST", "/v3/file/exists", request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// DirectoryExists calls /v3/dir/exists
+func (client *Client) DirectoryExists(parentUUID string, nameHashed string) (*ItemExists, error) {
+	request := struct {
+		ParentUUID string `json:"parent"`
+		NameHashed string `json:"nameHashed"`
+	}{parentUUID, nameHashed}
+	response := &ItemExists{}
+	_, err := client.Request("POST", "/v3/dir/exists", request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// /v3/dir/download
+
+type DirectoryTree struct {
+	Files   []DirectoryTreeFile   `json:"files"`
+	Folders []DirectoryTreeFolder `json:"folders"`
+}
+
+type DirectoryTreeFile struct {
+	UUID      string                 `json:"uuid"`
+	Bucket    string                 `json:"bucket"`
+	Region    string                 `json:"region"`
+	Chunks    int                    `json:"chunks"`
+	Parent    string                 `json:"parent"`
+	Metadata  crypto.EncryptedString `json:"metadata"`
+	Version   int                    `json:"version"`
+	Timestamp int                    `json:"timestamp"`
+}
+
+type DirectoryTreeFolder struct {
+	UUID      string                 `json:"uuid"`
+	Name      crypto.EncryptedString `json:"name"`
+	Parent    string                 `json:"parent"`
+	Timestamp int                    `json:"timestamp"`
+}
+
+// GetDirectoryTree calls /v3/dir/download
+func (client *Client) GetDirectoryTree(uuid string) (*DirectoryTree, error) {
+	request := struct {
+		UUID string `json:"uuid"`
+		Type string `json:"type"`
+	}{uuid, "normal"}
+	response := &DirectoryTree{}
+	_, err := client.Request("POST", "/v3/dir/download", request, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
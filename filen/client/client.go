@@ -51,7 +51,9 @@ var (
 
 // Client carries configuration.
 type Client struct {
-	APIKey string // the Filen API key
+	APIKey           string    // the Filen API key
+	UploadThrottle   *Throttle // limits the upload rate of file chunks (nil means unlimited)
+	DownloadThrottle *Throttle // limits the download rate of file chunks (nil means unlimited)
 }
 
 // A RequestError carries information on a failed HTTP request.
@@ -120,6 +122,9 @@ func (client *Client) Request(method string, path string, request any, data any)
 	if err != nil {
 		return nil, &RequestError{fmt.Sprintf("Cannot unmarshal response %s", string(resBody)), method, path, nil}
 	}
+	if !response.Status {
+		return nil, &RequestError{fmt.Sprintf("Request failed: %s (%s)", response.Message, response.Code), method, path, nil}
+	}
 	if data != nil { // data wanted
 		if response.Data == nil {
 			return nil, &RequestError{fmt.Sprintf("No data in response %s", string(resBody)), method, path, nil}
@@ -162,7 +167,7 @@ func (client *Client) DownloadFileChunk(uuid string, region string, bucket strin
 		return nil, err
 	}
 
-	data, err := io.ReadAll(res.Body)
+	data, err := io.ReadAll(client.DownloadThrottle.Reader(res.Body))
 	if err != nil {
 		return nil, err
 	}
@@ -176,10 +181,16 @@ func (client *Client) UploadFileChunk(uuid string, chunkIdx int, parentUUID stri
 	dataHash := hex.EncodeToString(crypto.RunSHA521(data))
 	url := fmt.Sprintf("%s/v3/upload?uuid=%s&index=%v&parent=%s&uploadKey=%s&hash=%s",
 		ingestURL, uuid, chunkIdx, parentUUID, uploadKey, dataHash)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	req, err := http.NewRequest("POST", url, client.UploadThrottle.Reader(bytes.NewReader(data)))
 	if err != nil {
 		return "", "", err
 	}
+	req.ContentLength = int64(len(data))
+	// allow net/http to replay the request (e.g. when a kept-alive connection turns out to be closed),
+	// which it can't do on its own for a throttled body
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(client.UploadThrottle.Reader(bytes.NewReader(data))), nil
+	}
 	req.Header.Set("Authorization", "Bearer "+client.APIKey)
 
 	// send request
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingTransport answers every request successfully and records the bodies it was sent,
+// and the bodies GetBody returns for replaying the request.
+type recordingTransport struct {
+	body       []byte
+	replayBody []byte
+}
+
+func (transport *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var err error
+	transport.body, err = io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		transport.replayBody, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{"status":true,"data":{"region":"r","bucket":"b"}}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestUploadFileChunkReplayable(t *testing.T) {
+	transport := &recordingTransport{}
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = transport
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	data := bytes.Repeat([]byte("chunk"), 1000)
+	tests := []struct {
+		name     string
+		throttle *Throttle
+	}{
+		{"no throttle", nil},
+		{"unlimited", NewThrottle(func(time.Time) Bandwidth { return Bandwidth{} })},
+		{"limited", NewThrottle(func(time.Time) Bandwidth { return Bandwidth{Rate: 1 << 30} })},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			*transport = recordingTransport{}
+			client := &Client{UploadThrottle: test.throttle}
+			_, _, err := client.UploadFileChunk("uuid", 0, "parent", "key", data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(transport.body, data) {
+				t.Errorf("sent %d bytes, want %d", len(transport.body), len(data))
+			}
+			if !bytes.Equal(transport.replayBody, data) {
+				t.Errorf("GetBody returned %d bytes, want %d", len(transport.replayBody), len(data))
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// Bandwidth is a transfer rate limit.
+type Bandwidth struct {
+	Rate  int64 // the sustained rate in bytes per second (zero or less means unlimited)
+	Burst int64 // how many bytes may be transferred at once after being idle (defaults to Rate)
+}
+
+const (
+	throttleBlockSize = 32 * 1024              // the granularity in which throttled readers transfer data
+	throttleMaxSleep  = 250 * time.Millisecond // how long to wait at most before re-evaluating the limit
+)
+
+// A Throttle limits the rate at which bytes are transferred using a token bucket.
+// The limit is looked up on every transfer, so it can be changed at any time.
+// A nil *Throttle does not limit anything.
+type Throttle struct {
+	limit  func(now time.Time) Bandwidth
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewThrottle creates a Throttle that applies the Bandwidth returned by limit.
+func NewThrottle(limit func(now time.Time) Bandwidth) *Throttle {
+	return &Throttle{limit: limit}
+}
+
+// Wait blocks until n bytes may be transferred.
+func (throttle *Throttle) Wait(n int) {
+	if throttle == nil {
+		return
+	}
+	for n > 0 {
+		bandwidth := throttle.limit(time.Now())
+		if bandwidth.Rate <= 0 {
+			return
+		}
+		burst := bandwidth.Burst
+		if burst <= 0 {
+			burst = bandwidth.Rate
+		}
+		want := int(min(int64(n), burst))
+		wait := throttle.take(want, bandwidth.Rate, burst)
+		if wait == 0 {
+			n -= want
+			continue
+		}
+		// sleep in bounded steps, as the limit might change in the meantime
+		time.Sleep(min(wait, throttleMaxSleep))
+	}
+}
+
+// take consumes want tokens from a bucket of the given rate and size if they are available.
+// Otherwise, it returns how long it will take until they are.
+func (throttle *Throttle) take(want int, rate int64, burst int64) time.Duration {
+	throttle.mu.Lock()
+	defer throttle.mu.Unlock()
+
+	now := time.Now()
+	if throttle.last.IsZero() {
+		throttle.tokens = float64(burst)
+	} else {
+		throttle.tokens = min(throttle.tokens+now.Sub(throttle.last).Seconds()*float64(rate), float64(burst))
+	}
+	throttle.last = now
+
+	if throttle.tokens >= float64(want) {
+		throttle.tokens -= float64(want)
+		return 0
+	}
+	missing := float64(want) - throttle.tokens
+	return max(time.Duration(missing/float64(rate)*float64(time.Second)), time.Millisecond)
+}
+
+// Reader wraps r so that reading from it is throttled.
+// If no limit applies at the time of the call, r is returned as it is.
+func (throttle *Throttle) Reader(r io.Reader) io.Reader {
+	if throttle == nil || throttle.limit(time.Now()).Rate <= 0 {
+		return r
+	}
+	return &throttledReader{throttle, r}
+}
+
+type throttledReader struct {
+	throttle *Throttle
+	reader   io.Reader
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > throttleBlockSize {
+		p = p[:throttleBlockSize]
+	}
+	n, err := r.reader.Read(p)
+	r.throttle.Wait(n)
+	return n, err
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestThrottleTake(t *testing.T) {
+	throttle := NewThrottle(nil)
+	tests := []struct {
+		name    string
+		want    int
+		minWait time.Duration
+		maxWait time.Duration
+	}{
+		{"full bucket", 1000, 0, 0},
+		{"empty bucket", 500, 400 * time.Millisecond, 500 * time.Millisecond},
+		{"empty bucket, more than half", 1000, 900 * time.Millisecond, time.Second},
+	}
+	for _, test := range tests {
+		wait := throttle.take(test.want, 1000, 1000)
+		if wait < test.minWait || wait > test.maxWait {
+			t.Errorf("%s: take(%d) waits %v, want between %v and %v", test.name, test.want, wait, test.minWait, test.maxWait)
+		}
+	}
+}
+
+func TestThrottleUnlimited(t *testing.T) {
+	var throttle *Throttle
+	throttle.Wait(1 << 30)
+	r := bytes.NewReader(nil)
+	if throttle.Reader(r) != r {
+		t.Error("nil throttle wraps the reader")
+	}
+
+	throttle = NewThrottle(func(time.Time) Bandwidth { return Bandwidth{} })
+	if throttle.Reader(r) != r {
+		t.Error("unlimited throttle wraps the reader")
+	}
+	start := time.Now()
+	throttle.Wait(1 << 30)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("unlimited throttle waited %v", elapsed)
+	}
+}
+
+func TestThrottleRate(t *testing.T) {
+	const rate = 100 * 1024
+	throttle := NewThrottle(func(time.Time) Bandwidth { return Bandwidth{Rate: rate, Burst: 10 * 1024} })
+	start := time.Now()
+	n, err := io.Copy(io.Discard, throttle.Reader(bytes.NewReader(make([]byte, 40*1024))))
+	if err != nil || n != 40*1024 {
+		t.Fatalf("copied %d bytes, err %v", n, err)
+	}
+	// the burst is available immediately, the remaining 30 KiB take 300ms
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("reading 40 KiB took %v, want about 300ms", elapsed)
+	}
+}
+
+func TestThrottleLimitChange(t *testing.T) {
+	var rate atomic.Int64
+	rate.Store(1)
+	throttle := NewThrottle(func(time.Time) Bandwidth { return Bandwidth{Rate: rate.Load()} })
+	throttle.Wait(1) // use up the burst
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		rate.Store(0)
+	}()
+	start := time.Now()
+	throttle.Wait(10) // would take 10s at the initial rate
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("raising the limit took effect after %v", elapsed)
+	}
+}
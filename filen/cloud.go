@@ -1,13 +1,16 @@
 package filen
 
 import (
+	"crypto/sha1"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
 	"github.com/FilenCloudDienste/filen-sdk-go/filen/crypto"
 	"github.com/FilenCloudDienste/filen-sdk-go/filen/util"
 	"github.com/google/uuid"
+	"io/fs"
 	"strings"
 	"time"
 )
@@ -30,28 +33,117 @@ type File struct {
 
 // Directory represents a directory on the cloud drive.
 type Directory struct {
-	UUID       string    // the UUID of the cloud item
-	Name       string    // the directory name
-	ParentUUID string    // the [Directory.UUID] of the directory's parent directory (or zero value for the root directory)
-	Color      string    // the color assigned to the directory (zero value means default color)
-	Created    time.Time // when the directory was created
-	Favorited  bool      // whether the directory is marked a favorite
+	UUID       string         // the UUID of the cloud item
+	Name       string         // the directory name
+	ParentUUID string         // the [Directory.UUID] of the directory's parent directory (or zero value for the root directory)
+	Color      DirectoryColor // the color assigned to the directory (zero value means default color)
+	Created    time.Time      // when the directory was created
+	Favorited  bool           // whether the directory is marked a favorite
+}
+
+// fileMetadata is the file metadata that is stored encrypted with the master key.
+type fileMetadata struct {
+	Name         string `json:"name"`
+	Size         int    `json:"size"`
+	MimeType     string `json:"mime"`
+	Key          string `json:"key"`
+	LastModified int    `json:"lastModified"`
+	Created      int    `json:"created,omitempty"`
+}
+
+// An ItemExistsError denotes that an operation was rejected because its target directory
+// already contains an item with the same name.
+type ItemExistsError struct {
+	ParentUUID string // the UUID of the directory that contains the item
+	Name       string // the name of the item
+	UUID       string // the UUID of the existing item
+}
+
+func (e *ItemExistsError) Error() string {
+	return fmt.Sprintf("an item named %q already exists in directory %s", e.Name, e.ParentUUID)
+}
+
+// hashName computes the hashed name by which the API identifies items within a directory, the same way
+// the official clients do for accounts using auth version 2 (which the SDK logs in with):
+// the hex-encoded SHA-1 hash of the hex-encoded SHA-512 hash of the lower-cased name.
+func hashName(name string) string {
+	sha512Hex := hex.EncodeToString(crypto.RunSHA521([]byte(strings.ToLower(name))))
+	sha1Hash := sha1.Sum([]byte(sha512Hex))
+	return hex.EncodeToString(sha1Hash[:])
+}
+
+// encryptFileMetadata encrypts file metadata with the current master key.
+func (filen *Filen) encryptFileMetadata(metadata fileMetadata) (crypto.EncryptedString, error) {
+	metadataStr, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	return crypto.EncryptMetadata(string(metadataStr), filen.CurrentMasterKey())
+}
+
+// renamedFileMetadata fetches a file's metadata and encrypts it with the current master key after replacing the name.
+// Only the name is replaced, so that fields the SDK doesn't model (like "hash") are retained
+// and missing ones (like "created") aren't made up.
+func (filen *Filen) renamedFileMetadata(uuid string, name string) (crypto.EncryptedString, error) {
+	fileInfo, err := filen.client.GetFile(uuid)
+	if err != nil {
+		return "", err
+	}
+	metadataStr, err := crypto.DecryptMetadataAllKeys(fileInfo.Metadata, filen.MasterKeys)
+	if err != nil {
+		return "", err
+	}
+	renamed, err := replaceMetadataName(metadataStr, name)
+	if err != nil {
+		return "", err
+	}
+	return crypto.EncryptMetadata(string(renamed), filen.CurrentMasterKey())
+}
+
+// replaceMetadataName replaces the name in JSON-encoded file metadata, leaving all other fields as they are.
+func replaceMetadataName(metadataStr string, name string) ([]byte, error) {
+	var metadata map[string]json.RawMessage
+	err := json.Unmarshal([]byte(metadataStr), &metadata)
+	if err != nil {
+		return nil, err
+	}
+	metadata["name"], err = json.Marshal(name)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(metadata)
+}
+
+// encryptDirectoryName encrypts the metadata that holds a directory's name with the current master key.
+func (filen *Filen) encryptDirectoryName(name string) (crypto.EncryptedString, error) {
+	metadata := struct {
+		Name string `json:"name"`
+	}{name}
+	metadataStr, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	return crypto.EncryptMetadata(string(metadataStr), filen.CurrentMasterKey())
 }
 
 // GetBaseFolderUUID fetches the UUID of the cloud drive's root directory.
 func (filen *Filen) GetBaseFolderUUID() (string, error) {
+	if uuid, ok := filen.cache.directoryUUID("/"); ok {
+		return uuid, nil
+	}
 	userBaseFolder, err := filen.client.GetUserBaseFolder()
 	if err != nil {
 		return "", err
 	}
+	filen.cache.putDirectoryUUID("/", userBaseFolder.UUID)
 	return userBaseFolder.UUID, nil
 }
 
-// FindItemUUID finds a cloud item by its path and returns its UUID.
+// FindItemUUID finds a cloud item by its path (see [ParsePath]) and returns its UUID.
 // Returns an empty string if none was found.
 // Use this instead of FindItem to correctly handle paths pointing to the base directory.
 func (filen *Filen) FindItemUUID(path string, requireDirectory bool) (string, error) {
-	if len(strings.Join(strings.Split(path, "/"), "")) == 0 { // empty path
+	if ParsePath(path).IsRoot() {
 		baseFolderUUID, err := filen.GetBaseFolderUUID()
 		if err != nil {
 			return "", err
@@ -72,206 +164,548 @@ func (filen *Filen) FindItemUUID(path string, requireDirectory bool) (string, er
 	}
 }
 
-// FindItem find a cloud item by its path and returns it (either the File or the Directory will be returned).
+// FindItem find a cloud item by its path (see [ParsePath]) and returns it (either the File or the Directory will be returned).
 // Set requireDirectory to differentiate between files and directories with the same path (otherwise, the file will be found).
 // Returns nil for both File and Directory if none was found.
 func (filen *Filen) FindItem(path string, requireDirectory bool) (*File, *Directory, error) {
-	baseFolderUUID, err := filen.GetBaseFolderUUID()
-	if err != nil {
-		return nil, nil, err
+	segments := ParsePath(path).segments
+	if len(segments) == 0 {
+		return nil, nil, errors.New(fmt.Sprintf("no segments in path %s", path))
 	}
 
-	segments := strings.Split(path, "/")
-	if len(strings.Join(segments, "")) == 0 {
-		return nil, nil, errors.New(fmt.Sprintf("no segments in path %s", path))
+	// start at the deepest parent directory whose UUID is cached
+	matching := filen.NameMatching()
+	currentUUID, resolved, err := filen.findCachedDirectory(matching, segments[:len(segments)-1])
+	if err != nil {
+		return nil, nil, err
 	}
 
-	currentUUID := baseFolderUUID
-SegmentsLoop:
-	for segmentIdx, segment := range segments {
-		if segment == "" {
-			continue
-		}
+	for segmentIdx := resolved; segmentIdx < len(segments); segmentIdx++ {
+		segmentKey := matching.key(segments[segmentIdx])
+		lastSegment := segmentIdx == len(segments)-1
 
-		files, directories, err := filen.ReadDirectory(currentUUID)
+		// stop listing at the first match (files are listed first, so they take precedence)
+		var foundFile *File
+		var foundDirectory *Directory
+		err := filen.listDirectory(currentUUID, lastSegment && !requireDirectory, func(file *File, directory *Directory) error {
+			if file != nil && matching.key(file.Name) == segmentKey {
+				foundFile = file
+				return fs.SkipAll
+			}
+			if directory != nil && matching.key(directory.Name) == segmentKey {
+				foundDirectory = directory
+				return fs.SkipAll
+			}
+			return nil
+		})
 		if err != nil {
 			return nil, nil, err
 		}
-		if !requireDirectory {
-			for _, file := range files {
-				if file.Name == segment {
-					return file, nil, nil
-				}
-			}
-		}
-		for _, directory := range directories {
-			if directory.Name == segment {
-				if segmentIdx == len(segments)-1 {
-					return nil, directory, nil
-				} else {
-					currentUUID = directory.UUID
-					continue SegmentsLoop
-				}
-			}
+
+		switch {
+		case foundFile != nil:
+			return foundFile, nil, nil
+		case foundDirectory == nil:
+			return nil, nil, nil
+		case lastSegment:
+			return nil, foundDirectory, nil
 		}
-		return nil, nil, nil
+		currentUUID = foundDirectory.UUID
+		filen.cache.putDirectoryUUID(cachePath(matching, segments[:segmentIdx+1]), currentUUID)
 	}
 	return nil, nil, errors.New("unreachable")
 }
 
-// FindDirectoryOrCreate finds a cloud directory by its path and returns its UUID.
-// If the directory cannot be found, it (and all non-existent parent directories) will be created.
-func (filen *Filen) FindDirectoryOrCreate(path string) (string, error) {
+// findCachedDirectory returns the UUID of the deepest directory along the path given by segments
+// whose UUID is cached, along with the number of segments leading to it (0 for the base folder).
+func (filen *Filen) findCachedDirectory(matching NameMatching, segments []string) (string, int, error) {
+	for resolved := len(segments); resolved > 0; resolved-- {
+		if uuid, ok := filen.cache.directoryUUID(cachePath(matching, segments[:resolved])); ok {
+			return uuid, resolved, nil
+		}
+	}
 	baseFolderUUID, err := filen.GetBaseFolderUUID()
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
+	return baseFolderUUID, 0, nil
+}
 
-	segments := strings.Split(path, "/")
-	if len(strings.Join(segments, "")) == 0 {
-		return baseFolderUUID, nil
+// FindDirectoryOrCreate finds a cloud directory by its path (see [ParsePath]) and returns its UUID.
+// If the directory cannot be found, it (and all non-existent parent directories) will be created.
+func (filen *Filen) FindDirectoryOrCreate(path string) (string, error) {
+	segments := ParsePath(path).segments
+	matching := filen.NameMatching()
+	currentUUID, resolved, err := filen.findCachedDirectory(matching, segments)
+	if err != nil {
+		return "", err
 	}
 
-	currentUUID := baseFolderUUID
-SegmentsLoop:
-	for _, segment := range segments {
-		if segment == "" {
-			continue
-		}
-
-		_, directories, err := filen.ReadDirectory(currentUUID)
+	for segmentIdx := resolved; segmentIdx < len(segments); segmentIdx++ {
+		segment := segments[segmentIdx]
+		exists, directoryUUID, err := filen.DirectoryExists(currentUUID, segment)
 		if err != nil {
 			return "", err
 		}
-		for _, directory := range directories {
-			if directory.Name == segment {
-				// directory found
-				currentUUID = directory.UUID
-				continue SegmentsLoop
+		if exists {
+			// directory found
+			currentUUID = directoryUUID
+		} else {
+			// create directory
+			directory, err := filen.CreateDirectory(currentUUID, segment)
+			if err != nil {
+				return "", err
 			}
+			currentUUID = directory.UUID
 		}
-		// create directory
-		directory, err := filen.CreateDirectory(currentUUID, segment)
-		if err != nil {
-			return "", err
-		}
-		currentUUID = directory.UUID
+		filen.cache.putDirectoryUUID(cachePath(matching, segments[:segmentIdx+1]), currentUUID)
 	}
 	return currentUUID, nil
 }
 
 // ReadDirectory fetches the files and directories that are children of a directory (specified by UUID).
+// If the metadata cache is enabled (see [CacheOptions]), cached listings are used, and the returned items must not be modified.
 func (filen *Filen) ReadDirectory(uuid string) ([]*File, []*Directory, error) {
+	if files, directories, ok := filen.cache.listing(uuid); ok {
+		return files, directories, nil
+	}
+
 	// fetch directory content
 	directoryContent, err := filen.client.GetDirectoryContent(uuid)
 	if err != nil {
 		return nil, nil, err
 	}
+	files, directories, err := filen.decryptDirectoryContent(directoryContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	filen.cache.putListing(uuid, files, directories)
+	return files, directories, nil
+}
+
+// ReadDirectoryLenient is like [Filen.ReadDirectory], but items whose metadata cannot be decrypted or parsed
+// (e.g. because they were encrypted with a foreign key) don't make the listing fail. Instead, all other items
+// are returned, along with an [*ItemError] for each item that was left out.
+// An error is only returned if the directory could not be listed at all.
+func (filen *Filen) ReadDirectoryLenient(uuid string) ([]*File, []*Directory, []*ItemError, error) {
+	if files, directories, ok := filen.cache.listing(uuid); ok {
+		return files, directories, nil, nil
+	}
+
+	// fetch directory content
+	directoryContent, err := filen.client.GetDirectoryContent(uuid)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	files, directories, itemErrors := filen.decryptDirectoryContentLenient(directoryContent)
+	if len(itemErrors) == 0 {
+		// only complete listings are cached, as ReadDirectory uses them too
+		filen.cache.putListing(uuid, files, directories)
+	}
+	return files, directories, itemErrors, nil
+}
+
+// An ItemError describes an item of a directory listing that could not be decrypted or parsed.
+type ItemError struct {
+	UUID      string // the UUID of the item
+	Directory bool   // whether the item is a directory (rather than a file)
+	Err       error  // the reason the item could not be read
+}
+
+func (e *ItemError) Error() string {
+	itemType := "file"
+	if e.Directory {
+		itemType = "directory"
+	}
+	return fmt.Sprintf("cannot read %s %s: %v", itemType, e.UUID, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// decryptDirectoryContent transforms the files and directories of a directory listing.
+// If any item cannot be read, its [*ItemError] is returned.
+func (filen *Filen) decryptDirectoryContent(directoryContent *client.DirectoryContent) ([]*File, []*Directory, error) {
+	files, directories, itemErrors := filen.decryptDirectoryContentLenient(directoryContent)
+	if len(itemErrors) > 0 {
+		return nil, nil, itemErrors[0]
+	}
+	return files, directories, nil
+}
+
+// decryptDirectoryContentLenient transforms the files and directories of a directory listing
+// and collects the errors of the items that cannot be read. The items are decrypted in parallel.
+func (filen *Filen) decryptDirectoryContentLenient(directoryContent *client.DirectoryContent) ([]*File, []*Directory, []*ItemError) {
+	itemErrors := make([]*ItemError, 0)
 
 	// transform files
-	files := make([]*File, 0)
-	for _, file := range directoryContent.Uploads {
-		metadataStr, err := crypto.DecryptMetadataAllKeys(file.Metadata, filen.MasterKeys)
-		if err != nil {
-			return nil, nil, err
-		}
-		var metadata struct {
-			Name         string `json:"name"`
-			Size         int    `json:"size"`
-			MimeType     string `json:"mime"`
-			Key          string `json:"key"`
-			LastModified int    `json:"lastModified"`
-		}
-		err = json.Unmarshal([]byte(metadataStr), &metadata)
-		if err != nil {
-			return nil, nil, err
+	decryptedFiles, errs := parallelMapAll(directoryContent.Uploads, filen.newFile)
+	files := make([]*File, 0, len(decryptedFiles))
+	for i, file := range decryptedFiles {
+		if errs[i] != nil {
+			itemErrors = append(itemErrors, &ItemError{directoryContent.Uploads[i].UUID, false, errs[i]})
+			continue
 		}
-
-		files = append(files, &File{
-			UUID:          file.UUID,
-			Name:          metadata.Name,
-			Size:          int64(metadata.Size),
-			MimeType:      metadata.MimeType,
-			EncryptionKey: []byte(metadata.Key),
-			Created:       util.TimestampToTime(int64(file.Timestamp)),
-			LastModified:  util.TimestampToTime(int64(metadata.LastModified)),
-			ParentUUID:    file.Parent,
-			Favorited:     file.Favorited == 1,
-			Region:        file.Region,
-			Bucket:        file.Bucket,
-			Chunks:        file.Chunks,
-		})
+		files = append(files, file)
 	}
 
 	// transform directories
-	directories := make([]*Directory, 0)
-	for _, directory := range directoryContent.Folders {
-		nameStr, err := crypto.DecryptMetadataAllKeys(directory.Name, filen.MasterKeys)
-		if err != nil {
-			return nil, nil, err
-		}
-		var name struct {
-			Name string `json:"name"`
-		}
-		err = json.Unmarshal([]byte(nameStr), &name)
-		if err != nil {
-			return nil, nil, err
+	decryptedDirectories, errs := parallelMapAll(directoryContent.Folders, filen.newDirectory)
+	directories := make([]*Directory, 0, len(decryptedDirectories))
+	for i, directory := range decryptedDirectories {
+		if errs[i] != nil {
+			itemErrors = append(itemErrors, &ItemError{directoryContent.Folders[i].UUID, true, errs[i]})
+			continue
 		}
+		directories = append(directories, directory)
+	}
 
-		directories = append(directories, &Directory{
-			UUID:       directory.UUID,
-			Name:       name.Name,
-			ParentUUID: directory.Parent,
-			Color:      "<none>", //TODO tmp
-			Created:    util.TimestampToTime(int64(directory.Timestamp)),
-			Favorited:  directory.Favorited == 1,
-		})
+	return files, directories, itemErrors
+}
+
+// newFile decrypts a file entry of a directory listing.
+func (filen *Filen) newFile(upload client.DirectoryContentUpload) (*File, error) {
+	metadata, err := filen.decryptFileMetadata(upload.Metadata)
+	if err != nil {
+		return nil, err
 	}
+	return &File{
+		UUID:          upload.UUID,
+		Name:          metadata.Name,
+		Size:          int64(metadata.Size),
+		MimeType:      metadata.MimeType,
+		EncryptionKey: []byte(metadata.Key),
+		Created:       util.TimestampToTime(int64(upload.Timestamp)),
+		LastModified:  util.TimestampToTime(int64(metadata.LastModified)),
+		ParentUUID:    upload.Parent,
+		Favorited:     upload.Favorited == 1,
+		Region:        upload.Region,
+		Bucket:        upload.Bucket,
+		Chunks:        upload.Chunks,
+	}, nil
+}
 
-	return files, directories, nil
+// newDirectory decrypts a directory entry of a directory listing.
+func (filen *Filen) newDirectory(folder client.DirectoryContentFolder) (*Directory, error) {
+	name, err := filen.decryptDirectoryName(folder.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &Directory{
+		UUID:       folder.UUID,
+		Name:       name,
+		ParentUUID: folder.Parent,
+		Color:      parseDirectoryColor(folder.Color),
+		Created:    util.TimestampToTime(int64(folder.Timestamp)),
+		Favorited:  folder.Favorited == 1,
+	}, nil
+}
+
+// decryptFileMetadata decrypts file metadata using any of the master keys.
+func (filen *Filen) decryptFileMetadata(metadataEncrypted crypto.EncryptedString) (fileMetadata, error) {
+	var metadata fileMetadata
+	metadataStr, err := crypto.DecryptMetadataAllKeys(metadataEncrypted, filen.MasterKeys)
+	if err != nil {
+		return metadata, err
+	}
+	err = json.Unmarshal([]byte(metadataStr), &metadata)
+	return metadata, err
+}
+
+// decryptDirectoryName decrypts the metadata that holds a directory's name using any of the master keys.
+func (filen *Filen) decryptDirectoryName(nameEncrypted crypto.EncryptedString) (string, error) {
+	nameStr, err := crypto.DecryptMetadataAllKeys(nameEncrypted, filen.MasterKeys)
+	if err != nil {
+		return "", err
+	}
+	var name struct {
+		Name string `json:"name"`
+	}
+	err = json.Unmarshal([]byte(nameStr), &name)
+	if err != nil {
+		return "", err
+	}
+	return name.Name, nil
 }
 
 // TrashFile moves a file to trash.
 func (filen *Filen) TrashFile(uuid string) error {
+	defer filen.cache.invalidateItem(uuid)
 	return filen.client.TrashFile(uuid)
 }
 
 // CreateDirectory creates a new directory.
 func (filen *Filen) CreateDirectory(parentUUID string, name string) (*Directory, error) {
-	directoryUUID := uuid.New().String()
-
-	// encrypt metadata
-	metadata := struct {
-		Name string `json:"name"`
-	}{name}
-	metadataStr, err := json.Marshal(metadata)
+	name, err := filen.newItemName(name)
 	if err != nil {
 		return nil, err
 	}
-	metadataEncrypted, err := crypto.EncryptMetadata(string(metadataStr), filen.CurrentMasterKey())
+	directoryUUID := uuid.New().String()
+
+	// encrypt metadata
+	metadataEncrypted, err := filen.encryptDirectoryName(name)
 	if err != nil {
 		return nil, err
 	}
 
 	// hash name
-	nameHashed := hex.EncodeToString(crypto.RunSHA521([]byte(name)))
+	nameHashed := hashName(name)
 
 	// send
 	response, err := filen.client.CreateDirectory(directoryUUID, metadataEncrypted, nameHashed, parentUUID)
 	if err != nil {
 		return nil, err
 	}
+	filen.cache.invalidateDirectory(parentUUID)
 	return &Directory{
 		UUID:       response.UUID,
 		Name:       name,
 		ParentUUID: parentUUID,
-		Color:      "",
+		Color:      DirectoryColorDefault,
 		Created:    time.Now(),
 		Favorited:  false,
 	}, nil
 }
 
+// RenameFile renames a file and returns the renamed file.
+// It fails with an [*ItemExistsError] if the file's parent directory already contains a file with the new name.
+func (filen *Filen) RenameFile(file *File, name string) (*File, error) {
+	name, err := filen.newItemName(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == file.Name {
+		return file, nil
+	}
+	exists, existingUUID, err := filen.FileExists(file.ParentUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists && existingUUID != file.UUID { // the name might only differ in a way the name matching mode ignores
+		return nil, &ItemExistsError{file.ParentUUID, name, existingUUID}
+	}
+
+	// encrypt name and metadata
+	nameEncrypted, err := crypto.EncryptMetadata(name, file.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	metadataEncrypted, err := filen.renamedFileMetadata(file.UUID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// send
+	err = filen.client.RenameFile(file.UUID, nameEncrypted, hashName(name), metadataEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	filen.cache.invalidateItem(file.UUID)
+	renamed := *file
+	renamed.Name = name
+	return &renamed, nil
+}
+
+// RenameDirectory renames a directory and returns the renamed directory.
+// It fails with an [*ItemExistsError] if the directory's parent directory already contains a directory with the new name.
+func (filen *Filen) RenameDirectory(directory *Directory, name string) (*Directory, error) {
+	name, err := filen.newItemName(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == directory.Name {
+		return directory, nil
+	}
+	exists, existingUUID, err := filen.DirectoryExists(directory.ParentUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists && existingUUID != directory.UUID {
+		return nil, &ItemExistsError{directory.ParentUUID, name, existingUUID}
+	}
+
+	// encrypt metadata
+	metadataEncrypted, err := filen.encryptDirectoryName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// send
+	err = filen.client.RenameDirectory(directory.UUID, metadataEncrypted, hashName(name))
+	if err != nil {
+		return nil, err
+	}
+	filen.cache.invalidateItem(directory.UUID)
+	renamed := *directory
+	renamed.Name = name
+	return &renamed, nil
+}
+
+// ErrMoveIntoSubdirectory is returned when trying to move a directory into itself or one of its subdirectories.
+var ErrMoveIntoSubdirectory = errors.New("cannot move a directory into itself or one of its subdirectories")
+
+// MoveFile moves a file into another directory (specified by UUID) and returns the moved file.
+// It fails with an [*ItemExistsError] if the destination already contains a file with the same name.
+func (filen *Filen) MoveFile(file *File, parentUUID string) (*File, error) {
+	if parentUUID == file.ParentUUID {
+		return file, nil
+	}
+	exists, existingUUID, err := filen.FileExists(parentUUID, file.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &ItemExistsError{parentUUID, file.Name, existingUUID}
+	}
+
+	err = filen.client.MoveFile(file.UUID, parentUUID)
+	if err != nil {
+		return nil, err
+	}
+	filen.cache.invalidateItem(file.UUID)
+	filen.cache.invalidateDirectory(parentUUID)
+	moved := *file
+	moved.ParentUUID = parentUUID
+	return &moved, nil
+}
+
+// MoveDirectory moves a directory into another directory (specified by UUID) and returns the moved directory.
+// It fails with [ErrMoveIntoSubdirectory] if the destination is the directory itself or lies within it,
+// and with an [*ItemExistsError] if the destination already contains a directory with the same name.
+func (filen *Filen) MoveDirectory(directory *Directory, parentUUID string) (*Directory, error) {
+	if parentUUID == directory.ParentUUID {
+		return directory, nil
+	}
+
+	// make sure the destination is not within the directory by walking up from the destination
+	baseFolderUUID, err := filen.GetBaseFolderUUID()
+	if err != nil {
+		return nil, err
+	}
+	for ancestorUUID := parentUUID; ancestorUUID != baseFolderUUID && ancestorUUID != ""; {
+		if ancestorUUID == directory.UUID {
+			return nil, ErrMoveIntoSubdirectory
+		}
+		ancestor, err := filen.client.GetDirectory(ancestorUUID)
+		if err != nil {
+			return nil, err
+		}
+		ancestorUUID = ancestor.Parent
+	}
+
+	exists, existingUUID, err := filen.DirectoryExists(parentUUID, directory.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, &ItemExistsError{parentUUID, directory.Name, existingUUID}
+	}
+
+	err = filen.client.MoveDirectory(directory.UUID, parentUUID)
+	if err != nil {
+		return nil, err
+	}
+	filen.cache.invalidateItem(directory.UUID)
+	filen.cache.invalidateDirectory(parentUUID)
+	moved := *directory
+	moved.ParentUUID = parentUUID
+	return &moved, nil
+}
+
+// Move moves the cloud item at a path into the directory at destinationPath (see [Filen.FindItem]).
+// It returns the moved item (either the File or the Directory will be returned).
+func (filen *Filen) Move(path string, destinationPath string) (*File, *Directory, error) {
+	file, directory, err := filen.FindItem(path, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	if file == nil && directory == nil {
+		return nil, nil, fmt.Errorf("no such item: %s", path)
+	}
+	destinationUUID, err := filen.FindItemUUID(destinationPath, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	if destinationUUID == "" {
+		return nil, nil, fmt.Errorf("no such directory: %s", destinationPath)
+	}
+
+	if file != nil {
+		file, err = filen.MoveFile(file, destinationUUID)
+		return file, nil, err
+	} else {
+		directory, err = filen.MoveDirectory(directory, destinationUUID)
+		return nil, directory, err
+	}
+}
+
+// FileExists checks whether a directory (specified by UUID) contains a file with the given name,
+// without listing the directory. If it does, the file's UUID is returned.
+//
+// Like the official clients, the API compares names case-insensitively, so a file whose name only differs in case
+// is reported as well. In the name matching modes other than [NameMatchingExact], the API cannot compare
+// normalized names, so the directory is listed if the API doesn't find the name;
+// see [Filen.SetListingFallback] for doing the same in NameMatchingExact.
+func (filen *Filen) FileExists(parentUUID string, name string) (bool, string, error) {
+	response, err := filen.client.FileExists(parentUUID, hashName(name))
+	if err != nil {
+		return false, "", err
+	}
+	if response.Exists || !filen.listsOnMiss() {
+		return response.Exists, response.UUID, nil
+	}
+	return filen.findByName(parentUUID, name, false)
+}
+
+// DirectoryExists checks whether a directory (specified by UUID) contains a directory with the given name,
+// without listing the directory. If it does, the directory's UUID is returned.
+//
+// As with [Filen.FileExists], names are compared case-insensitively,
+// and the directory is only listed if necessary for the name matching mode or enabled by [Filen.SetListingFallback].
+func (filen *Filen) DirectoryExists(parentUUID string, name string) (bool, string, error) {
+	response, err := filen.client.DirectoryExists(parentUUID, hashName(name))
+	if err != nil {
+		return false, "", err
+	}
+	if response.Exists || !filen.listsOnMiss() {
+		return response.Exists, response.UUID, nil
+	}
+	return filen.findByName(parentUUID, name, true)
+}
+
+// namesMatch reports whether two names match in the current name matching mode.
+func (filen *Filen) namesMatch(name string, other string) bool {
+	matching := filen.NameMatching()
+	return matching.key(name) == matching.key(other)
+}
+
+// findByName lists a directory to find a file or directory by its decrypted name,
+// for when the API's existence check doesn't find it.
+// Items that cannot be decrypted are ignored.
+func (filen *Filen) findByName(parentUUID string, name string, directory bool) (bool, string, error) {
+	files, directories, _, err := filen.ReadDirectoryLenient(parentUUID)
+	if err != nil {
+		return false, "", err
+	}
+	if directory {
+		for _, directory := range directories {
+			if filen.namesMatch(directory.Name, name) {
+				return true, directory.UUID, nil
+			}
+		}
+	} else {
+		for _, file := range files {
+			if filen.namesMatch(file.Name, name) {
+				return true, file.UUID, nil
+			}
+		}
+	}
+	return false, "", nil
+}
+
 // TrashDirectory moves a directory to trash.
 func (filen *Filen) TrashDirectory(uuid string) error {
+	defer filen.cache.invalidateItem(uuid)
 	return filen.client.TrashDirectory(uuid)
 }
@@ -0,0 +1,59 @@
+package filen
+
+import (
+	"encoding/json"
+	"maps"
+	"testing"
+)
+
+func TestHashName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Test.txt", "809a953250a3917a9993645d1ba146348a198fc2"},
+		{"test.TXT", "809a953250a3917a9993645d1ba146348a198fc2"},
+		{"ordner", "3bf8ea1b4fe8c465f686f164104d43211994fe02"},
+	}
+	for _, test := range tests {
+		if got := hashName(test.name); got != test.want {
+			t.Errorf("hashName(%q) = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
+
+func TestReplaceMetadataName(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     map[string]any
+	}{
+		{
+			"unmodelled fields are retained",
+			`{"name":"old.txt","size":5,"mime":"text/plain","key":"k","lastModified":1700000000000,"creation":1600000000000,"hash":"abc"}`,
+			map[string]any{"name": "new.txt", "size": 5.0, "mime": "text/plain", "key": "k",
+				"lastModified": 1700000000000.0, "creation": 1600000000000.0, "hash": "abc"},
+		},
+		{
+			"missing timestamps aren't added",
+			`{"name":"old.txt","size":5,"mime":"text/plain","key":"k"}`,
+			map[string]any{"name": "new.txt", "size": 5.0, "mime": "text/plain", "key": "k"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			renamed, err := replaceMetadataName(test.metadata, "new.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			err = json.Unmarshal(renamed, &got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, test.want) {
+				t.Errorf("metadata = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package filen
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// A DirectoryColor is the color assigned to a directory in the official clients.
+// It is either [DirectoryColorDefault], one of the named colors, or a custom hex color like "#ff8800".
+type DirectoryColor string
+
+const (
+	DirectoryColorDefault DirectoryColor = ""
+	DirectoryColorBlue    DirectoryColor = "blue"
+	DirectoryColorGreen   DirectoryColor = "green"
+	DirectoryColorPurple  DirectoryColor = "purple"
+	DirectoryColorRed     DirectoryColor = "red"
+	DirectoryColorGray    DirectoryColor = "gray"
+)
+
+var (
+	namedDirectoryColors = []DirectoryColor{DirectoryColorBlue, DirectoryColorGreen, DirectoryColorPurple, DirectoryColorRed, DirectoryColorGray}
+	hexColorPattern      = regexp.MustCompile(`^#([0-9a-f]{3}|[0-9a-f]{6})$`)
+)
+
+// IsDefault returns whether the color is the default color.
+func (color DirectoryColor) IsDefault() bool {
+	return color == DirectoryColorDefault
+}
+
+// IsNamed returns whether the color is one of the named colors.
+func (color DirectoryColor) IsNamed() bool {
+	return slices.Contains(namedDirectoryColors, color)
+}
+
+// IsHex returns whether the color is a custom hex color.
+func (color DirectoryColor) IsHex() bool {
+	return hexColorPattern.MatchString(string(color))
+}
+
+// parseDirectoryColor decodes the color the API returns for a directory, which is null or "default" for the default color.
+func parseDirectoryColor(color interface{}) DirectoryColor {
+	colorStr, ok := color.(string)
+	if !ok || colorStr == "default" {
+		return DirectoryColorDefault
+	}
+	return DirectoryColor(strings.ToLower(colorStr))
+}
+
+// SetDirectoryColor assigns a color to a directory.
+func (filen *Filen) SetDirectoryColor(uuid string, color DirectoryColor) error {
+	color = DirectoryColor(strings.ToLower(string(color)))
+	if !color.IsDefault() && !color.IsNamed() && !color.IsHex() {
+		return fmt.Errorf("invalid directory color %q", color)
+	}
+	colorStr := string(color)
+	if color.IsDefault() {
+		colorStr = "default"
+	}
+	defer filen.cache.invalidateItem(uuid)
+	return filen.client.SetDirectoryColor(uuid, colorStr)
+}
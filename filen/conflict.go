@@ -0,0 +1,131 @@
+package filen
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// A ConflictPolicy determines what [Filen.UploadFile] does if the target directory
+// already contains a file with the same name.
+type ConflictPolicy int
+
+const (
+	// ConflictNoCheck uploads without checking for an existing file, possibly creating a second file with the same name.
+	ConflictNoCheck ConflictPolicy = iota
+	// ConflictReplace uploads the file as a new version of the existing file.
+	ConflictReplace
+	// ConflictSkip doesn't upload anything and returns the existing file instead.
+	ConflictSkip
+	// ConflictFail doesn't upload anything and fails with an [*ItemExistsError].
+	ConflictFail
+	// ConflictRename uploads the file under the first free name of the form "name (1).ext".
+	// If necessary, the name is shortened so that it doesn't exceed [MaxNameLength].
+	ConflictRename
+)
+
+// WithConflictPolicy sets the [ConflictPolicy] of an upload (the default is [ConflictNoCheck]).
+func WithConflictPolicy(policy ConflictPolicy) TransferOption {
+	return func(options *transferOptions) {
+		options.conflictPolicy = policy
+	}
+}
+
+// resolveUploadConflict checks whether a directory already contains a file named fileName and applies the policy.
+// It returns the name to upload the file under, and the existing file, if any.
+func (filen *Filen) resolveUploadConflict(fileName string, parentUUID string, policy ConflictPolicy) (string, *File, error) {
+	exists, existingUUID, err := filen.FileExists(parentUUID, fileName)
+	if err != nil {
+		return "", nil, err
+	}
+	if !exists {
+		return fileName, nil, nil
+	}
+
+	switch policy {
+	case ConflictFail:
+		return "", nil, &ItemExistsError{parentUUID, fileName, existingUUID}
+	case ConflictRename:
+		extension := filepath.Ext(fileName)
+		base := strings.TrimSuffix(fileName, extension)
+		if base == "" { // e.g. ".bashrc"
+			base, extension = fileName, ""
+		}
+		exists, err := filen.candidateExists(parentUUID)
+		if err != nil {
+			return "", nil, err
+		}
+		for i := 1; ; i++ {
+			candidate, err := numberedName(base, extension, i)
+			if err != nil {
+				return "", nil, err
+			}
+			candidateExists, err := exists(candidate)
+			if err != nil {
+				return "", nil, err
+			}
+			if !candidateExists {
+				return candidate, nil, nil
+			}
+		}
+	default:
+		existing, err := filen.GetFile(existingUUID)
+		if err != nil {
+			return "", nil, err
+		}
+		return fileName, existing, nil
+	}
+}
+
+// candidateExists returns a function that checks whether a directory contains a file with a candidate name.
+// If directories are listed when the API's existence check doesn't find a name (see [Filen.SetListingFallback]),
+// the directory is listed once up front instead of once per candidate.
+func (filen *Filen) candidateExists(parentUUID string) (func(name string) (bool, error), error) {
+	if !filen.listsOnMiss() {
+		return func(name string) (bool, error) {
+			exists, _, err := filen.FileExists(parentUUID, name)
+			return exists, err
+		}, nil
+	}
+	files, _, _, err := filen.ReadDirectoryLenient(parentUUID)
+	if err != nil {
+		return nil, err
+	}
+	return func(name string) (bool, error) {
+		return slices.ContainsFunc(files, func(file *File) bool { return filen.namesMatch(file.Name, name) }), nil
+	}, nil
+}
+
+// numberedName returns the name "base (i)extension", shortening base if the name would exceed MaxNameLength.
+func numberedName(base string, extension string, i int) (string, error) {
+	suffix := fmt.Sprintf(" (%d)%s", i, extension)
+	if len(base)+len(suffix) > MaxNameLength {
+		n := max(MaxNameLength-len(suffix), 0)
+		for n > 0 && !utf8.RuneStart(base[n]) {
+			n--
+		}
+		base = base[:n]
+	}
+	name := base + suffix
+	err := ValidateName(name)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// replaceFile finishes replacing a file after a file with the same name has been uploaded.
+// The API usually turns the existing file into a previous version of the new one;
+// if it is still listed as a separate file, it is moved to trash instead.
+func (filen *Filen) replaceFile(existing *File) error {
+	files, _, err := filen.ReadDirectory(existing.ParentUUID)
+	if err != nil {
+		return err
+	}
+	if slices.ContainsFunc(files, func(file *File) bool { return file.UUID == existing.UUID }) {
+		return filen.TrashFile(existing.UUID)
+	}
+	return nil
+}
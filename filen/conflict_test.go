@@ -0,0 +1,42 @@
+package filen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNumberedName(t *testing.T) {
+	tests := []struct {
+		name      string
+		base      string
+		extension string
+		i         int
+		want      string
+		wantErr   error
+	}{
+		{"simple", "report", ".pdf", 1, "report (1).pdf", nil},
+		{"no extension", "notes", "", 12, "notes (12)", nil},
+		{"exactly at the limit", strings.Repeat("a", MaxNameLength-8), ".txt", 1, strings.Repeat("a", MaxNameLength-8) + " (1).txt", nil},
+		{"shortened", strings.Repeat("a", MaxNameLength), ".txt", 1, strings.Repeat("a", MaxNameLength-8) + " (1).txt", nil},
+		{"shortened more for longer numbers", strings.Repeat("a", MaxNameLength), ".txt", 100, strings.Repeat("a", MaxNameLength-10) + " (100).txt", nil},
+		// "ä" is 2 bytes long, so one of them has to go entirely
+		{"shortened at a character boundary", strings.Repeat("ä", 125), ".txt", 1, strings.Repeat("ä", 123) + " (1).txt", nil},
+		{"extension too long", "a", "." + strings.Repeat("b", MaxNameLength), 1, "", ErrNameTooLong},
+		{"invalid base", "a\x00", "", 1, "", ErrNameInvalidCharacters},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := numberedName(test.base, test.extension, test.i)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("err = %v, want %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("name = %q, want %q", got, test.want)
+			}
+			if err == nil && len(got) > MaxNameLength {
+				t.Errorf("name is %d bytes long", len(got))
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package filen
+
+import (
+	"sync"
+)
+
+const defaultCopyParallelism = 4
+
+// CopyFile copies a file into a directory (specified by UUID) and returns the copy.
+// The file's content is streamed from the cloud into a new upload with a fresh file key,
+// preserving its name, MIME type and last modification time.
+// Empty files cannot be copied, since they cannot be uploaded ([ErrEmptyFile]).
+func (filen *Filen) CopyFile(file *File, parentUUID string, opts ...TransferOption) (*File, error) {
+	if file.Size == 0 {
+		return nil, ErrEmptyFile
+	}
+	opts = append([]TransferOption{WithMimeType(file.MimeType), WithLastModified(file.LastModified)}, opts...)
+	return filen.UploadFile(file.Name, parentUUID, filen.NewFileReader(file), opts...)
+}
+
+// A CopyFailure describes an item that was not copied by [Filen.CopyDirectory].
+type CopyFailure struct {
+	Path string // the path of the item, relative to the copied directory
+	Err  error  // the reason the item could not be copied
+}
+
+// CopyResult summarizes the outcome of [Filen.CopyDirectory].
+type CopyResult struct {
+	Directory   *Directory    // the copy of the directory
+	Files       int           // the number of files that were copied
+	Directories int           // the number of directories that were copied, including the copied directory itself
+	Failures    []CopyFailure // the items that could not be copied
+	Skipped     []CopyFailure // the items that were not copied because they cannot be, i.e. empty files ([ErrEmptyFile])
+}
+
+// CopyDirectory copies a directory with all its content into another directory (specified by UUID).
+// Up to parallelism files are copied at the same time (a default is used if parallelism is zero or less).
+//
+// Copying continues when individual items fail; these are reported in [CopyResult.Failures].
+// Empty files are skipped, as they cannot be uploaded, and reported in [CopyResult.Skipped].
+// An error is only returned if the copy of the directory itself could not be created.
+func (filen *Filen) CopyDirectory(directory *Directory, parentUUID string, parallelism int) (*CopyResult, error) {
+	if parallelism <= 0 {
+		parallelism = defaultCopyParallelism
+	}
+
+	directoryCopy, err := filen.CreateDirectory(parentUUID, directory.Name)
+	if err != nil {
+		return nil, err
+	}
+	result := &CopyResult{Directory: directoryCopy, Directories: 1}
+
+	var mu sync.Mutex // protects result
+	fail := func(path string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Failures = append(result.Failures, CopyFailure{path, err})
+	}
+	skip := func(path string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		result.Skipped = append(result.Skipped, CopyFailure{path, err})
+	}
+
+	// directories are created by a single goroutine walking the tree, while files are copied concurrently
+	copySem := make(chan int, parallelism)
+	var copiers sync.WaitGroup
+	created := map[string]bool{directoryCopy.UUID: true} // guards against copying the copies when copying into a subdirectory
+
+	var copyContent func(sourceUUID string, destinationUUID string, path string)
+	copyContent = func(sourceUUID string, destinationUUID string, path string) {
+		files, directories, err := filen.ReadDirectory(sourceUUID)
+		if err != nil {
+			fail(path, err)
+			return
+		}
+
+		for _, file := range files {
+			if file.Size == 0 {
+				skip(path+file.Name, ErrEmptyFile)
+				continue
+			}
+			copySem <- 1
+			copiers.Add(1)
+			go func() {
+				defer copiers.Done()
+				defer func() { <-copySem }()
+
+				_, err := filen.CopyFile(file, destinationUUID)
+				if err != nil {
+					fail(path+file.Name, err)
+					return
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				result.Files++
+			}()
+		}
+
+		for _, subdirectory := range directories {
+			if created[subdirectory.UUID] {
+				continue
+			}
+			subdirectoryCopy, err := filen.CreateDirectory(destinationUUID, subdirectory.Name)
+			if err != nil {
+				fail(path+subdirectory.Name, err)
+				continue
+			}
+			created[subdirectoryCopy.UUID] = true
+			mu.Lock()
+			result.Directories++
+			mu.Unlock()
+			copyContent(subdirectory.UUID, subdirectoryCopy.UUID, path+subdirectory.Name+"/")
+		}
+	}
+	copyContent(directory.UUID, directoryCopy.UUID, "")
+	copiers.Wait()
+
+	return result, nil
+}
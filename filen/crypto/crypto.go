@@ -9,7 +9,6 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"slices"
 )
 
 // EncryptedString denotes that a string is encrypted and can't be used meaningfully before being decrypted.
@@ -71,9 +70,8 @@ func (e *AllKeysFailedError) Error() string {
 // DecryptMetadataAllKeys calls [DecryptMetadata] using all provided keys.
 func DecryptMetadataAllKeys(metadata EncryptedString, keys [][]byte) (string, error) {
 	errors := make([]error, 0)
-	slices.Reverse(keys)
-	for _, key := range keys {
-		decrypted, err := DecryptMetadata(metadata, key)
+	for i := len(keys) - 1; i >= 0; i-- { // try the most recent key first, without modifying keys
+		decrypted, err := DecryptMetadata(metadata, keys[i])
 		if err != nil {
 			errors = append(errors, err)
 		} else {
@@ -85,6 +83,9 @@ func DecryptMetadataAllKeys(metadata EncryptedString, keys [][]byte) (string, er
 
 // DecryptMetadata decrypts metadata.
 func DecryptMetadata(metadata EncryptedString, key []byte) (string, error) {
+	if len(metadata) < 15 {
+		return "", fmt.Errorf("encrypted metadata too short (%d bytes)", len(metadata))
+	}
 	nonce := metadata[3:15]
 	encrypted, err := base64.StdEncoding.DecodeString(string(metadata[15:]))
 	if err != nil {
@@ -100,6 +101,9 @@ func DecryptMetadata(metadata EncryptedString, key []byte) (string, error) {
 
 // DecryptData decrypts file data.
 func DecryptData(data []byte, key []byte) ([]byte, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("encrypted data too short (%d bytes)", len(data))
+	}
 	nonce, ciphertext := data[:12], data[12:]
 	result, err := runAES256GCMDecryption(key, nonce, ciphertext)
 	if err != nil {
@@ -0,0 +1,25 @@
+package filen
+
+// favoritesDirectoryUUID is the pseudo directory UUID by which the API lists all favorites.
+const favoritesDirectoryUUID = "favorites"
+
+// SetFileFavorite marks a file as favorite, or removes the mark.
+func (filen *Filen) SetFileFavorite(uuid string, favorite bool) error {
+	defer filen.cache.invalidateItem(uuid)
+	return filen.client.SetFavorite(uuid, "file", favorite)
+}
+
+// SetDirectoryFavorite marks a directory as favorite, or removes the mark.
+func (filen *Filen) SetDirectoryFavorite(uuid string, favorite bool) error {
+	defer filen.cache.invalidateItem(uuid)
+	return filen.client.SetFavorite(uuid, "folder", favorite)
+}
+
+// ListFavorites fetches the files and directories across the cloud drive that are marked as favorite.
+func (filen *Filen) ListFavorites() ([]*File, []*Directory, error) {
+	directoryContent, err := filen.client.GetDirectoryContent(favoritesDirectoryUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filen.decryptDirectoryContent(directoryContent)
+}
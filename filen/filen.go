@@ -5,6 +5,7 @@ import (
 	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
 	"github.com/FilenCloudDienste/filen-sdk-go/filen/crypto"
 	"strings"
+	"sync"
 )
 
 // Filen provides the SDK interface. Needs to be initialized via [New].
@@ -17,6 +18,24 @@ type Filen struct {
 	// their password, a new master key is appended. For decryption, all master keys are tried
 	// until one works; for decryption, always use the latest master key.
 	MasterKeys [][]byte
+
+	limitsMu  sync.Mutex
+	limits    ConcurrencyLimits
+	transfers transferLimiter // shared by all transfers to limit the total number of in-flight chunk requests
+
+	bandwidthMu       sync.Mutex
+	bandwidthLimits   BandwidthLimits
+	bandwidthSchedule []BandwidthRule
+
+	incompleteUploadsMu sync.Mutex
+	incompleteUploads   []*IncompleteUpload // failed uploads whose chunks could not be purged yet
+	purges              sync.WaitGroup      // background purges of failed uploads
+
+	cache metadataCache
+
+	nameMatchingMu  sync.Mutex
+	nameMatching    NameMatching
+	listingFallback bool // see SetListingFallback
 }
 
 // New creates a new Filen and initializes it with the given email and password
@@ -26,6 +45,8 @@ func New(email, password string) (*Filen, error) {
 		Email:  email,
 		client: &client.Client{},
 	}
+	filen.SetConcurrencyLimits(ConcurrencyLimits{})
+	filen.client.UploadThrottle, filen.client.DownloadThrottle = filen.newThrottles()
 
 	// fetch salt
 	authInfo, err := filen.client.GetAuthInfo(email)
@@ -0,0 +1,321 @@
+// Package filenfs provides read-only access to a Filen drive through the [io/fs] interfaces,
+// so that it can be used with e.g. [net/http.FS], template loaders or [testing/fstest].
+package filenfs
+
+import (
+	"bytes"
+	"errors"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen"
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Backend is the part of [filen.Filen] the file system is built on. It can be substituted, e.g. for testing.
+//
+// If the Backend also has a method NewFileReader(*filen.File) *filen.FileReader (like [filen.Filen]),
+// file content is streamed chunk by chunk; otherwise, it is downloaded entirely when it is first read.
+type Backend interface {
+	GetBaseFolderUUID() (string, error)
+	FindItem(path string, requireDirectory bool) (*filen.File, *filen.Directory, error)
+	ReadDirectory(uuid string) ([]*filen.File, []*filen.Directory, error)
+	DownloadFile(file *filen.File, chunkHandler func(chunk int, data []byte) error, opts ...filen.TransferOption) error
+}
+
+type fileReaderBackend interface {
+	NewFileReader(file *filen.File) *filen.FileReader
+}
+
+// FS is a read-only file system on a Filen drive.
+// It implements [fs.FS], [fs.ReadDirFS], [fs.StatFS] and [fs.ReadFileFS].
+type FS struct {
+	backend Backend
+	root    string
+}
+
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+)
+
+// New creates an FS for the directory at the given path on a Filen drive ("" or "/" for the whole drive).
+func New(backend Backend, root string) *FS {
+	return &FS{backend, strings.Trim(root, "/")}
+}
+
+// cloudPath converts a name as used by [fs.FS] to a path on the drive.
+func (fsys *FS) cloudPath(name string) string {
+	if name == "." {
+		return "/" + fsys.root
+	}
+	return "/" + path.Join(fsys.root, name)
+}
+
+// find finds the item with the given name (either the File or the Directory will be returned).
+func (fsys *FS) find(op string, name string) (*filen.File, *filen.Directory, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	if name == "." && fsys.root == "" {
+		baseFolderUUID, err := fsys.backend.GetBaseFolderUUID()
+		if err != nil {
+			return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		return nil, &filen.Directory{UUID: baseFolderUUID}, nil
+	}
+	file, directory, err := fsys.backend.FindItem(fsys.cloudPath(name), name == ".")
+	if err != nil {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if file == nil && directory == nil {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return file, directory, nil
+}
+
+// Open opens the named file or directory.
+func (fsys *FS) Open(name string) (fs.File, error) {
+	file, directory, err := fsys.find("open", name)
+	if err != nil {
+		return nil, err
+	}
+	if directory != nil {
+		return &openDirectory{fsys: fsys, name: name, directory: directory}, nil
+	}
+	return &openFile{fsys: fsys, name: name, file: file}, nil
+}
+
+// Stat returns a [fs.FileInfo] describing the named file or directory.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	file, directory, err := fsys.find("stat", name)
+	if err != nil {
+		return nil, err
+	}
+	return NewFileInfo(path.Base(name), file, directory), nil
+}
+
+// ReadDir reads the named directory and returns its entries sorted by name.
+func (fsys *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	_, directory, err := fsys.find("readdir", name)
+	if err != nil {
+		return nil, err
+	}
+	if directory == nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: errors.New("not a directory")}
+	}
+	return fsys.readDirectory(name, directory)
+}
+
+func (fsys *FS) readDirectory(name string, directory *filen.Directory) ([]fs.DirEntry, error) {
+	files, directories, err := fsys.backend.ReadDirectory(directory.UUID)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+	entries := make([]fs.DirEntry, 0, len(files)+len(directories))
+	for _, file := range files {
+		entries = append(entries, fs.FileInfoToDirEntry(NewFileInfo(file.Name, file, nil)))
+	}
+	for _, directory := range directories {
+		entries = append(entries, fs.FileInfoToDirEntry(NewFileInfo(directory.Name, nil, directory)))
+	}
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
+
+// ReadFile reads the named file and returns its content.
+func (fsys *FS) ReadFile(name string) ([]byte, error) {
+	file, _, err := fsys.find("read", name)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: errors.New("is a directory")}
+	}
+	data, err := fsys.download(file)
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return data, nil
+}
+
+// download downloads the entire content of a file.
+func (fsys *FS) download(file *filen.File) ([]byte, error) {
+	data := make([]byte, file.Size)
+	err := fsys.backend.DownloadFile(file, func(chunk int, chunkData []byte) error {
+		copy(data[min(int64(chunk)*filen.ChunkSize, file.Size):], chunkData)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// fileInfo implements fs.FileInfo for files and directories.
+type fileInfo struct {
+	name      string
+	file      *filen.File
+	directory *filen.Directory
+}
+
+// NewFileInfo returns a [fs.FileInfo] for a cloud file or directory (one of file and directory must be nil),
+// as returned by [FS.Stat].
+func NewFileInfo(name string, file *filen.File, directory *filen.Directory) fs.FileInfo {
+	return &fileInfo{name, file, directory}
+}
+
+func (info *fileInfo) Name() string {
+	return info.name
+}
+
+func (info *fileInfo) Size() int64 {
+	if info.file != nil {
+		return info.file.Size
+	}
+	return 0
+}
+
+func (info *fileInfo) Mode() fs.FileMode {
+	if info.directory != nil {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// ModTime returns when a file was last modified, or when a directory was created.
+func (info *fileInfo) ModTime() time.Time {
+	if info.file != nil {
+		return info.file.LastModified
+	}
+	return info.directory.Created
+}
+
+func (info *fileInfo) IsDir() bool {
+	return info.directory != nil
+}
+
+// Sys returns the underlying *filen.File or *filen.Directory.
+func (info *fileInfo) Sys() any {
+	if info.file != nil {
+		return info.file
+	}
+	return info.directory
+}
+
+// openFile is an opened file. Besides fs.File, it implements io.Seeker and io.ReaderAt.
+type openFile struct {
+	fsys    *FS
+	name    string
+	file    *filen.File
+	content io.ReadSeeker // opened when first needed
+	closed  bool
+}
+
+func (file *openFile) Stat() (fs.FileInfo, error) {
+	return NewFileInfo(path.Base(file.name), file.file, nil), nil
+}
+
+// open prepares reading the file's content.
+func (file *openFile) open(op string) error {
+	if file.closed {
+		return &fs.PathError{Op: op, Path: file.name, Err: fs.ErrClosed}
+	}
+	if file.content != nil {
+		return nil
+	}
+	if backend, ok := file.fsys.backend.(fileReaderBackend); ok {
+		file.content = backend.NewFileReader(file.file)
+		return nil
+	}
+	data, err := file.fsys.download(file.file)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: file.name, Err: err}
+	}
+	file.content = bytes.NewReader(data)
+	return nil
+}
+
+func (file *openFile) Read(p []byte) (int, error) {
+	if err := file.open("read"); err != nil {
+		return 0, err
+	}
+	return file.content.Read(p)
+}
+
+func (file *openFile) Seek(offset int64, whence int) (int64, error) {
+	if err := file.open("seek"); err != nil {
+		return 0, err
+	}
+	return file.content.Seek(offset, whence)
+}
+
+func (file *openFile) ReadAt(p []byte, off int64) (int, error) {
+	if err := file.open("read"); err != nil {
+		return 0, err
+	}
+	return file.content.(io.ReaderAt).ReadAt(p, off)
+}
+
+func (file *openFile) Close() error {
+	if file.closed {
+		return &fs.PathError{Op: "close", Path: file.name, Err: fs.ErrClosed}
+	}
+	file.closed = true
+	return nil
+}
+
+// openDirectory is an opened directory, implementing fs.ReadDirFile.
+type openDirectory struct {
+	fsys      *FS
+	name      string
+	directory *filen.Directory
+	entries   []fs.DirEntry // read when first needed
+	offset    int
+	closed    bool
+}
+
+func (directory *openDirectory) Stat() (fs.FileInfo, error) {
+	return NewFileInfo(path.Base(directory.name), nil, directory.directory), nil
+}
+
+func (directory *openDirectory) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: directory.name, Err: errors.New("is a directory")}
+}
+
+func (directory *openDirectory) ReadDir(n int) ([]fs.DirEntry, error) {
+	if directory.closed {
+		return nil, &fs.PathError{Op: "readdir", Path: directory.name, Err: fs.ErrClosed}
+	}
+	if directory.entries == nil {
+		entries, err := directory.fsys.readDirectory(directory.name, directory.directory)
+		if err != nil {
+			return nil, err
+		}
+		directory.entries = entries
+	}
+
+	remaining := directory.entries[directory.offset:]
+	if n <= 0 {
+		directory.offset = len(directory.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	directory.offset += n
+	return remaining[:n], nil
+}
+
+func (directory *openDirectory) Close() error {
+	if directory.closed {
+		return &fs.PathError{Op: "close", Path: directory.name, Err: fs.ErrClosed}
+	}
+	directory.closed = true
+	return nil
+}
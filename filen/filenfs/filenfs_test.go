@@ -0,0 +1,158 @@
+package filenfs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// memBackend is a Backend that keeps a drive in memory.
+type memBackend struct {
+	files       map[string][]*filen.File      // by parent UUID
+	directories map[string][]*filen.Directory // by parent UUID
+	content     map[string][]byte             // by file UUID
+	lastUUID    int
+}
+
+func newMemBackend() *memBackend {
+	return &memBackend{
+		files:       make(map[string][]*filen.File),
+		directories: make(map[string][]*filen.Directory),
+		content:     make(map[string][]byte),
+	}
+}
+
+func (backend *memBackend) newUUID() string {
+	backend.lastUUID++
+	return fmt.Sprintf("uuid-%d", backend.lastUUID)
+}
+
+func (backend *memBackend) addDirectory(parentUUID string, name string) string {
+	directory := &filen.Directory{UUID: backend.newUUID(), Name: name, ParentUUID: parentUUID, Created: time.Unix(1700000000, 0)}
+	backend.directories[parentUUID] = append(backend.directories[parentUUID], directory)
+	return directory.UUID
+}
+
+func (backend *memBackend) addFile(parentUUID string, name string, content []byte) {
+	file := &filen.File{
+		UUID:         backend.newUUID(),
+		Name:         name,
+		Size:         int64(len(content)),
+		ParentUUID:   parentUUID,
+		LastModified: time.Unix(1700000000, 0),
+		Chunks:       (len(content) + filen.ChunkSize - 1) / filen.ChunkSize,
+	}
+	backend.files[parentUUID] = append(backend.files[parentUUID], file)
+	backend.content[file.UUID] = content
+}
+
+func (backend *memBackend) GetBaseFolderUUID() (string, error) {
+	return "root", nil
+}
+
+func (backend *memBackend) FindItem(path string, requireDirectory bool) (*filen.File, *filen.Directory, error) {
+	segments := filen.ParsePath(path).Segments()
+	current := &filen.Directory{UUID: "root"}
+	for i, segment := range segments {
+		var next *filen.Directory
+		for _, directory := range backend.directories[current.UUID] {
+			if directory.Name == segment {
+				next = directory
+			}
+		}
+		if next == nil && i == len(segments)-1 && !requireDirectory {
+			for _, file := range backend.files[current.UUID] {
+				if file.Name == segment {
+					return file, nil, nil
+				}
+			}
+		}
+		if next == nil {
+			return nil, nil, nil
+		}
+		current = next
+	}
+	return nil, current, nil
+}
+
+func (backend *memBackend) ReadDirectory(uuid string) ([]*filen.File, []*filen.Directory, error) {
+	return backend.files[uuid], backend.directories[uuid], nil
+}
+
+// DownloadFile passes the content in chunks like [filen.Filen.DownloadFile], i.e. not at all for empty files.
+func (backend *memBackend) DownloadFile(file *filen.File, chunkHandler func(chunk int, data []byte) error, _ ...filen.TransferOption) error {
+	content := backend.content[file.UUID]
+	for chunk := 0; chunk < file.Chunks; chunk++ {
+		err := chunkHandler(chunk, content[chunk*filen.ChunkSize:min((chunk+1)*filen.ChunkSize, len(content))])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTestBackend() (*memBackend, []byte) {
+	large := make([]byte, 2*filen.ChunkSize+5)
+	for i := range large {
+		large[i] = byte(i)
+	}
+	backend := newMemBackend()
+	backend.addFile("root", "hello.txt", []byte("hello"))
+	sub := backend.addDirectory("root", "sub")
+	backend.addFile(sub, "large.bin", large)
+	backend.addFile(sub, "empty", nil)
+	backend.addDirectory(sub, "deeper")
+	return backend, large
+}
+
+func TestFS(t *testing.T) {
+	backend, _ := newTestBackend()
+	tests := []struct {
+		root     string
+		expected []string
+	}{
+		{"", []string{"hello.txt", "sub", "sub/large.bin", "sub/empty", "sub/deeper"}},
+		{"/sub/", []string{"large.bin", "empty", "deeper"}},
+	}
+	for _, test := range tests {
+		t.Run(test.root, func(t *testing.T) {
+			err := fstest.TestFS(New(backend, test.root), test.expected...)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	backend, large := newTestBackend()
+	fsys := New(backend, "")
+	tests := []struct {
+		name    string
+		want    []byte
+		wantErr error
+	}{
+		{"hello.txt", []byte("hello"), nil},
+		{"sub/large.bin", large, nil},
+		{"sub/empty", []byte{}, nil},
+		{"missing", nil, fs.ErrNotExist},
+		{"sub/missing/file", nil, fs.ErrNotExist},
+		{"/hello.txt", nil, fs.ErrInvalid},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := fsys.ReadFile(test.name)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("err = %v, want %v", err, test.wantErr)
+			}
+			if !bytes.Equal(data, test.want) {
+				t.Errorf("read %d bytes, want %d", len(data), len(test.want))
+			}
+		})
+	}
+}
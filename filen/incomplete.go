@@ -0,0 +1,161 @@
+package filen
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/crypto"
+	"slices"
+	"sync"
+	"time"
+)
+
+// An IncompleteUpload is an upload that failed or was cancelled after some of its chunks had already been stored.
+// These chunks consume storage quota until they are purged using [Filen.PurgeIncompleteUpload].
+//
+// The exported fields describe the upload completely, so that it can be persisted and purged by a later process.
+type IncompleteUpload struct {
+	UUID           string    // the UUID the file would have had
+	Name           string    // the name of the file that was being uploaded
+	ParentUUID     string    // the UUID of the directory the file was being uploaded to
+	UploadKey      string    // the key that authorizes uploads of chunks for the file
+	EncryptionKey  []byte    // the key the file's chunks were encrypted with
+	UploadedChunks []int     // the indices of the chunks that were stored
+	UploadedBytes  int64     // the total size of the stored chunks (before encryption)
+	Started        time.Time // when the upload was started
+	Placeholder    bool      // whether the upload has been completed as placeholder file, which only remains to be deleted
+
+	purgeMu sync.Mutex // serializes purges, e.g. a background purge and a call to PurgeIncompleteUploads
+	purged  bool       // whether the upload has been purged
+}
+
+// purgeDeleteAttempts is how often deleting the placeholder file of an incomplete upload is attempted.
+const purgeDeleteAttempts = 3
+
+// placeholderName returns the name of the file an incomplete upload is completed as in order to purge it.
+func (upload *IncompleteUpload) placeholderName() string {
+	return fmt.Sprintf(".incomplete-upload-%s", upload.UUID)
+}
+
+// IncompleteUploads returns the uploads of this Filen instance that could not be aborted and still need to be purged.
+func (filen *Filen) IncompleteUploads() []*IncompleteUpload {
+	filen.incompleteUploadsMu.Lock()
+	defer filen.incompleteUploadsMu.Unlock()
+	return slices.Clone(filen.incompleteUploads)
+}
+
+// PurgeIncompleteUploads calls [Filen.PurgeIncompleteUpload] for every upload returned by [Filen.IncompleteUploads].
+func (filen *Filen) PurgeIncompleteUploads() error {
+	errs := make([]error, 0)
+	for _, upload := range filen.IncompleteUploads() {
+		err := filen.PurgeIncompleteUpload(upload)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PurgeIncompleteUpload deletes the chunks of an incomplete upload from the storage backend.
+//
+// Stored chunks can only be deleted as part of a file, so the upload is completed as a placeholder file
+// named ".incomplete-upload-<UUID>" in its parent directory (storing filler chunks for any missing ones),
+// which is then deleted permanently.
+//
+// Deleting the placeholder file is retried a few times. If it still fails, the placeholder file remains
+// visible in the directory (with the upload's UUID, which the returned error includes), and the upload
+// stays in [Filen.IncompleteUploads] with Placeholder set, so that purging it again only retries the deletion.
+func (filen *Filen) PurgeIncompleteUpload(upload *IncompleteUpload) error {
+	upload.purgeMu.Lock()
+	defer upload.purgeMu.Unlock()
+	if upload.purged {
+		return nil
+	}
+
+	chunks := 0
+	for _, chunk := range upload.UploadedChunks {
+		chunks = max(chunks, chunk+1)
+	}
+
+	if chunks > 0 {
+		if !upload.Placeholder {
+			err := filen.completeIncompleteUpload(upload, chunks)
+			if err != nil {
+				// the upload might have been completed anyway if only the response was lost
+				if filen.DeleteFilePermanently(upload.UUID) != nil {
+					return fmt.Errorf("purge incomplete upload %s: %w", upload.UUID, err)
+				}
+			}
+			upload.Placeholder = true
+		}
+
+		var err error
+		for attempt := 1; attempt <= purgeDeleteAttempts; attempt++ {
+			err = filen.DeleteFilePermanently(upload.UUID)
+			if err == nil {
+				break
+			}
+			if attempt < purgeDeleteAttempts {
+				time.Sleep(time.Duration(attempt) * time.Second)
+			}
+		}
+		filen.cache.invalidateDirectory(upload.ParentUUID)
+		if err != nil {
+			return fmt.Errorf("purge incomplete upload %s: cannot delete placeholder file %s: %w", upload.UUID, upload.placeholderName(), err)
+		}
+	}
+
+	upload.purged = true
+	filen.incompleteUploadsMu.Lock()
+	defer filen.incompleteUploadsMu.Unlock()
+	filen.incompleteUploads = slices.DeleteFunc(filen.incompleteUploads, func(other *IncompleteUpload) bool {
+		return other.UUID == upload.UUID
+	})
+	return nil
+}
+
+// completeIncompleteUpload fills the gaps between the uploaded chunks and marks the upload as done.
+func (filen *Filen) completeIncompleteUpload(upload *IncompleteUpload, chunks int) error {
+	filler, err := crypto.EncryptData([]byte{0}, upload.EncryptionKey)
+	if err != nil {
+		return err
+	}
+	for chunk := 0; chunk < chunks; chunk++ {
+		if slices.Contains(upload.UploadedChunks, chunk) {
+			continue
+		}
+		_, _, err := filen.client.UploadFileChunk(upload.UUID, chunk, upload.ParentUUID, upload.UploadKey, filler)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the filler chunks contain a single byte each
+	size := upload.UploadedBytes + int64(chunks-len(upload.UploadedChunks))
+	now := int(time.Now().Unix())
+	_, err = filen.uploadDone(upload.UUID, upload.UploadKey, chunks, fileMetadata{
+		Name:         upload.placeholderName(),
+		Size:         int(size),
+		MimeType:     "application/octet-stream",
+		Key:          string(upload.EncryptionKey),
+		LastModified: now,
+		Created:      now,
+	})
+	return err
+}
+
+// abortUpload purges a failed upload in the background, once the uploads of its chunks that are still in flight
+// (tracked by uploaders) have finished. Until then, and if purging fails, the upload is tracked in incompleteUploads.
+func (filen *Filen) abortUpload(upload *IncompleteUpload, uploaders *sync.WaitGroup) {
+	filen.purges.Add(1)
+	go func() {
+		defer filen.purges.Done()
+		uploaders.Wait()
+		if len(upload.UploadedChunks) == 0 {
+			return
+		}
+		filen.incompleteUploadsMu.Lock()
+		filen.incompleteUploads = append(filen.incompleteUploads, upload)
+		filen.incompleteUploadsMu.Unlock()
+		_ = filen.PurgeIncompleteUpload(upload) // if this fails, the upload stays in incompleteUploads
+	}()
+}
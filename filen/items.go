@@ -0,0 +1,86 @@
+package filen
+
+import (
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/util"
+	"slices"
+	"strings"
+)
+
+// GetFile fetches a file by its UUID.
+// The API doesn't report whether the file is marked as favorite, so Favorited is always false.
+func (filen *Filen) GetFile(uuid string) (*File, error) {
+	fileInfo, err := filen.client.GetFile(uuid)
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := filen.decryptFileMetadata(fileInfo.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	file := &File{
+		UUID:          fileInfo.UUID,
+		Name:          metadata.Name,
+		Size:          int64(metadata.Size),
+		MimeType:      metadata.MimeType,
+		EncryptionKey: []byte(metadata.Key),
+		LastModified:  util.TimestampToTime(int64(metadata.LastModified)),
+		ParentUUID:    fileInfo.Parent,
+		Favorited:     false,
+		Region:        fileInfo.Region,
+		Bucket:        fileInfo.Bucket,
+		Chunks:        (metadata.Size + ChunkSize - 1) / ChunkSize,
+	}
+	if metadata.Created != 0 {
+		file.Created = util.TimestampToTime(int64(metadata.Created))
+	}
+	return file, nil
+}
+
+// GetDirectory fetches a directory by its UUID.
+// The API doesn't report when the directory was created, so Created is always the zero value.
+func (filen *Filen) GetDirectory(uuid string) (*Directory, error) {
+	directoryInfo, err := filen.client.GetDirectory(uuid)
+	if err != nil {
+		return nil, err
+	}
+	name, err := filen.decryptDirectoryName(directoryInfo.NameEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	return &Directory{
+		UUID:       directoryInfo.UUID,
+		Name:       name,
+		ParentUUID: directoryInfo.Parent,
+		Color:      parseDirectoryColor(directoryInfo.Color),
+		Favorited:  directoryInfo.Favorited,
+	}, nil
+}
+
+// GetDirectoryPath resolves the path of a directory (specified by UUID) by walking up its parents.
+// The path of the root directory is "/".
+func (filen *Filen) GetDirectoryPath(uuid string) (string, error) {
+	baseFolderUUID, err := filen.GetBaseFolderUUID()
+	if err != nil {
+		return "", err
+	}
+	segments := make([]string, 0)
+	for uuid != baseFolderUUID {
+		directory, err := filen.GetDirectory(uuid)
+		if err != nil {
+			return "", err
+		}
+		segments = append(segments, directory.Name)
+		uuid = directory.ParentUUID
+	}
+	slices.Reverse(segments)
+	return "/" + strings.Join(segments, "/"), nil
+}
+
+// GetFilePath resolves the path of a file by walking up its parents.
+func (filen *Filen) GetFilePath(file *File) (string, error) {
+	directoryPath, err := filen.GetDirectoryPath(file.ParentUUID)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(directoryPath, "/") + "/" + file.Name, nil
+}
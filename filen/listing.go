@@ -0,0 +1,86 @@
+package filen
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// listBatchSize is how many items of a listing [Filen.ListDirectory] decrypts at a time (in parallel).
+const listBatchSize = 256
+
+// ListDirectory calls fn for every item of a directory (specified by UUID), with either the File or the Directory set.
+// Files are passed before directories.
+//
+// Unlike [Filen.ReadDirectory], ListDirectory doesn't collect the items, but decrypts them in small batches
+// as they are passed to fn, so that huge directories can be processed without holding all items,
+// and the listing can be stopped early without decrypting the remaining items:
+// if fn returns [fs.SkipAll], ListDirectory stops and returns nil. Any other error returned by fn stops the listing
+// and is returned. If an item cannot be read, its [*ItemError] is returned.
+//
+// If the metadata cache is enabled (see [CacheOptions]), the complete listing is read and cached instead.
+func (filen *Filen) ListDirectory(uuid string, fn func(file *File, directory *Directory) error) error {
+	return filen.listDirectory(uuid, true, fn)
+}
+
+// listDirectory implements ListDirectory, optionally skipping the files (which are then not decrypted at all).
+func (filen *Filen) listDirectory(uuid string, includeFiles bool, fn func(file *File, directory *Directory) error) error {
+	err := filen.streamDirectory(uuid, includeFiles, fn)
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+func (filen *Filen) streamDirectory(uuid string, includeFiles bool, fn func(file *File, directory *Directory) error) error {
+	if filen.cache.active() {
+		files, directories, err := filen.ReadDirectory(uuid)
+		if err != nil {
+			return err
+		}
+		if includeFiles {
+			for _, file := range files {
+				if err := fn(file, nil); err != nil {
+					return err
+				}
+			}
+		}
+		for _, directory := range directories {
+			if err := fn(nil, directory); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	directoryContent, err := filen.client.GetDirectoryContent(uuid)
+	if err != nil {
+		return err
+	}
+	if includeFiles {
+		for start := 0; start < len(directoryContent.Uploads); start += listBatchSize {
+			batch := directoryContent.Uploads[start:min(start+listBatchSize, len(directoryContent.Uploads))]
+			files, errs := parallelMapAll(batch, filen.newFile)
+			for i, file := range files {
+				if errs[i] != nil {
+					return &ItemError{batch[i].UUID, false, errs[i]}
+				}
+				if err := fn(file, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for start := 0; start < len(directoryContent.Folders); start += listBatchSize {
+		batch := directoryContent.Folders[start:min(start+listBatchSize, len(directoryContent.Folders))]
+		directories, errs := parallelMapAll(batch, filen.newDirectory)
+		for i, directory := range directories {
+			if errs[i] != nil {
+				return &ItemError{batch[i].UUID, true, errs[i]}
+			}
+			if err := fn(nil, directory); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
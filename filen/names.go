@@ -0,0 +1,151 @@
+package filen
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxNameLength is the maximum length of the name of a file or directory in bytes (of its UTF-8 encoding).
+const MaxNameLength = 255
+
+// Reasons for names to be rejected by [ValidateName], wrapped in an [*InvalidNameError].
+var (
+	ErrEmptyName             = errors.New("name is empty")
+	ErrReservedName          = errors.New("name is reserved")
+	ErrNameContainsSlash     = errors.New("name contains a slash")
+	ErrNameTooLong           = fmt.Errorf("name is longer than %d bytes", MaxNameLength)
+	ErrNameInvalidCharacters = errors.New("name is not valid UTF-8 or contains control characters")
+)
+
+// An InvalidNameError denotes that a name cannot be used for a file or directory.
+type InvalidNameError struct {
+	Name   string // the rejected name
+	Reason error  // why the name was rejected, e.g. [ErrNameContainsSlash]
+}
+
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("invalid name %q: %v", e.Name, e.Reason)
+}
+
+func (e *InvalidNameError) Unwrap() error {
+	return e.Reason
+}
+
+// ValidateName checks whether a name can be used for a file or directory, i.e. whether the item could be found
+// by its path. It returns an [*InvalidNameError] if not. The SDK validates the names of all items it creates or renames.
+func ValidateName(name string) error {
+	var reason error
+	switch {
+	case name == "":
+		reason = ErrEmptyName
+	case name == "." || name == "..":
+		reason = ErrReservedName
+	case strings.Contains(name, "/"):
+		reason = ErrNameContainsSlash
+	case len(name) > MaxNameLength:
+		reason = ErrNameTooLong
+	case !utf8.ValidString(name) || strings.ContainsFunc(name, func(r rune) bool { return r < 0x20 || r == 0x7f }):
+		reason = ErrNameInvalidCharacters
+	default:
+		return nil
+	}
+	return &InvalidNameError{name, reason}
+}
+
+// NameMatching determines how names are compared when resolving paths (see [Filen.SetNameMatching]).
+type NameMatching int
+
+const (
+	// NameMatchingExact matches names that are byte-wise equal. This is the default.
+	NameMatchingExact NameMatching = iota
+	// NameMatchingNormalized matches names that are equal after Unicode normalization (NFC),
+	// e.g. names written on macOS (which uses decomposed characters) with the same names typed elsewhere.
+	NameMatchingNormalized
+	// NameMatchingCaseInsensitive matches names that are equal after Unicode normalization and case folding,
+	// like the file systems of Windows and macOS do.
+	NameMatchingCaseInsensitive
+)
+
+// SetNameMatching sets how names are compared when resolving paths, i.e. in [Filen.FindItem],
+// [Filen.FindDirectoryOrCreate], [Filen.FileExists] and [Filen.DirectoryExists], and everything built on them.
+//
+// Unless the mode is [NameMatchingExact], the names of new items (created, uploaded or renamed)
+// are normalized to NFC, so that they can be found by their normalized name. Their case is preserved.
+func (filen *Filen) SetNameMatching(matching NameMatching) {
+	filen.nameMatchingMu.Lock()
+	defer filen.nameMatchingMu.Unlock()
+	filen.nameMatching = matching
+}
+
+// NameMatching returns how names are compared when resolving paths.
+func (filen *Filen) NameMatching() NameMatching {
+	filen.nameMatchingMu.Lock()
+	defer filen.nameMatchingMu.Unlock()
+	return filen.nameMatching
+}
+
+// SetListingFallback sets whether [Filen.FileExists] and [Filen.DirectoryExists], and everything built on them,
+// list the directory and compare the decrypted names if the API doesn't find a name in [NameMatchingExact] mode.
+// This is disabled by default, as it makes creating items in large directories slow. It is only needed for drives
+// containing items whose hashed names were computed differently, such as items created by versions of the SDK
+// before the hashing of the official clients was adopted. In the other name matching modes, directories are listed anyway.
+func (filen *Filen) SetListingFallback(enabled bool) {
+	filen.nameMatchingMu.Lock()
+	defer filen.nameMatchingMu.Unlock()
+	filen.listingFallback = enabled
+}
+
+// ListingFallback returns whether the listing fallback is enabled (see [Filen.SetListingFallback]).
+func (filen *Filen) ListingFallback() bool {
+	filen.nameMatchingMu.Lock()
+	defer filen.nameMatchingMu.Unlock()
+	return filen.listingFallback
+}
+
+// listsOnMiss reports whether directories are listed when the API's existence check doesn't find a name.
+func (filen *Filen) listsOnMiss() bool {
+	filen.nameMatchingMu.Lock()
+	defer filen.nameMatchingMu.Unlock()
+	return filen.nameMatching != NameMatchingExact || filen.listingFallback
+}
+
+// key returns the form in which names are compared: two names match if their keys are equal.
+func (matching NameMatching) key(name string) string {
+	switch matching {
+	case NameMatchingNormalized:
+		return norm.NFC.String(name)
+	case NameMatchingCaseInsensitive:
+		// canonical caseless matching, see The Unicode Standard, section 3.13
+		return norm.NFC.String(cases.Fold().String(norm.NFD.String(name)))
+	default:
+		return name
+	}
+}
+
+// cachePrefix distinguishes the cached paths resolved in this mode from those resolved in other modes.
+func (matching NameMatching) cachePrefix() string {
+	switch matching {
+	case NameMatchingNormalized:
+		return "nfc:"
+	case NameMatchingCaseInsensitive:
+		return "fold:"
+	default:
+		return ""
+	}
+}
+
+// newItemName validates the name of a new item and returns the name under which it is created.
+func (filen *Filen) newItemName(name string) (string, error) {
+	if filen.NameMatching() != NameMatchingExact {
+		name = norm.NFC.String(name)
+	}
+	err := ValidateName(name)
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
@@ -0,0 +1,86 @@
+package filen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"file.txt", nil},
+		{".hidden", nil},
+		{"...", nil},
+		{"with space", nil},
+		{"ünïcödé", nil},
+		{strings.Repeat("a", MaxNameLength), nil},
+		{strings.Repeat("ä", MaxNameLength/2), nil},
+		{"", ErrEmptyName},
+		{".", ErrReservedName},
+		{"..", ErrReservedName},
+		{"a/b", ErrNameContainsSlash},
+		{"/", ErrNameContainsSlash},
+		{strings.Repeat("a", MaxNameLength+1), ErrNameTooLong},
+		{strings.Repeat("ä", MaxNameLength/2+1), ErrNameTooLong},
+		{"a\x00b", ErrNameInvalidCharacters},
+		{"line\nbreak", ErrNameInvalidCharacters},
+		{"tab\t", ErrNameInvalidCharacters},
+		{"del\x7f", ErrNameInvalidCharacters},
+		{"\xff", ErrNameInvalidCharacters},
+	}
+	for _, test := range tests {
+		err := ValidateName(test.name)
+		if !errors.Is(err, test.want) || (err == nil) != (test.want == nil) {
+			t.Errorf("ValidateName(%q) = %v, want %v", test.name, err, test.want)
+		}
+		var invalidNameError *InvalidNameError
+		if err != nil && (!errors.As(err, &invalidNameError) || invalidNameError.Name != test.name) {
+			t.Errorf("ValidateName(%q) returned %#v, not an *InvalidNameError for the name", test.name, err)
+		}
+	}
+}
+
+func TestNameMatchingKey(t *testing.T) {
+	const (
+		composed   = "caf\u00e9"  // with a precomposed "é"
+		decomposed = "cafe\u0301" // with "e" and a combining acute accent
+	)
+	tests := []struct {
+		matching NameMatching
+		a, b     string
+		match    bool
+	}{
+		{NameMatchingExact, "a", "a", true},
+		{NameMatchingExact, composed, decomposed, false},
+		{NameMatchingExact, "A", "a", false},
+		{NameMatchingNormalized, composed, decomposed, true},
+		{NameMatchingNormalized, "A", "a", false},
+		{NameMatchingCaseInsensitive, composed, strings.ToUpper(decomposed), true},
+		{NameMatchingCaseInsensitive, "Straße", "STRASSE", true},
+		{NameMatchingCaseInsensitive, "a", "b", false},
+	}
+	for _, test := range tests {
+		if match := test.matching.key(test.a) == test.matching.key(test.b); match != test.match {
+			t.Errorf("mode %d: %q and %q match: %v, want %v", test.matching, test.a, test.b, match, test.match)
+		}
+	}
+}
+
+func TestNewItemName(t *testing.T) {
+	filen := &Filen{}
+	name, err := filen.newItemName("Cafe\u0301")
+	if err != nil || name != "Cafe\u0301" {
+		t.Errorf("exact mode: newItemName = %q, %v, want the name unchanged", name, err)
+	}
+	filen.SetNameMatching(NameMatchingCaseInsensitive)
+	name, err = filen.newItemName("Cafe\u0301")
+	if err != nil || name != "Caf\u00e9" {
+		t.Errorf("case-insensitive mode: newItemName = %q, %v, want the case-preserving NFC form", name, err)
+	}
+	if _, err := filen.newItemName("a/b"); !errors.Is(err, ErrNameContainsSlash) {
+		t.Errorf("newItemName accepted an invalid name: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package filen
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelMap applies fn to all items using a pool of GOMAXPROCS workers and returns the results in the same order.
+// If fn fails for any item, one of the errors is returned.
+func parallelMap[T any, R any](items []T, fn func(item T) (R, error)) ([]R, error) {
+	results := make([]R, len(items))
+	workers := min(runtime.GOMAXPROCS(0), len(items))
+	if workers <= 1 {
+		for i, item := range items {
+			result, err := fn(item)
+			if err != nil {
+				return nil, err
+			}
+			results[i] = result
+		}
+		return results, nil
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	failed := make(chan struct{}) // closed on the first error, so that no further items are handed out
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				result, err := fn(items[i])
+				if err != nil {
+					errOnce.Do(func() {
+						firstErr = err
+						close(failed)
+					})
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+Distribute:
+	for i := range items {
+		select {
+		case indices <- i:
+		case <-failed:
+			break Distribute
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
+
+// parallelMapAll is like parallelMap, but applies fn to all items even if it fails for some,
+// and returns the results and errors of all items in the same order.
+func parallelMapAll[T any, R any](items []T, fn func(item T) (R, error)) ([]R, []error) {
+	type result struct {
+		value R
+		err   error
+	}
+	results, _ := parallelMap(items, func(item T) (result, error) {
+		value, err := fn(item)
+		return result{value, err}, nil
+	})
+	values := make([]R, len(items))
+	errs := make([]error, len(items))
+	for i, result := range results {
+		values[i], errs[i] = result.value, result.err
+	}
+	return values, errs
+}
@@ -0,0 +1,168 @@
+package filen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/crypto"
+	"runtime"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// withGOMAXPROCS runs fn with GOMAXPROCS set to n, so that the parallel code paths run on any machine.
+func withGOMAXPROCS(n int, fn func()) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(n))
+	fn()
+}
+
+func TestParallelMapOrder(t *testing.T) {
+	for _, procs := range []int{1, 4} {
+		for _, n := range []int{0, 1, 3, 100} {
+			t.Run(fmt.Sprintf("%d workers, %d items", procs, n), func(t *testing.T) {
+				items := make([]int, n)
+				want := make([]string, n)
+				for i := range items {
+					items[i] = i
+					want[i] = fmt.Sprint(i)
+				}
+				var results []string
+				var err error
+				withGOMAXPROCS(procs, func() {
+					results, err = parallelMap(items, func(item int) (string, error) {
+						// later items finish first
+						time.Sleep(time.Duration(n-item) * 10 * time.Microsecond)
+						return fmt.Sprint(item), nil
+					})
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !slices.Equal(results, want) {
+					t.Errorf("results = %v, want %v", results, want)
+				}
+			})
+		}
+	}
+}
+
+func TestParallelMapError(t *testing.T) {
+	errFailed := errors.New("failed")
+	for _, procs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("%d workers", procs), func(t *testing.T) {
+			items := make([]int, 1000)
+			for i := range items {
+				items[i] = i
+			}
+			var calls atomic.Int64
+			var results []int
+			var err error
+			withGOMAXPROCS(procs, func() {
+				results, err = parallelMap(items, func(item int) (int, error) {
+					calls.Add(1)
+					if item == 10 {
+						return 0, fmt.Errorf("item %d: %w", item, errFailed)
+					}
+					return item, nil
+				})
+			})
+			if !errors.Is(err, errFailed) {
+				t.Errorf("err = %v, want %v", err, errFailed)
+			}
+			if results != nil {
+				t.Errorf("results = %v, want nil", results)
+			}
+			// no further items are handed out after the error
+			if calls.Load() > int64(len(items)/2) {
+				t.Errorf("fn called %d times", calls.Load())
+			}
+		})
+	}
+}
+
+func TestParallelMapAll(t *testing.T) {
+	errOdd := errors.New("odd")
+	withGOMAXPROCS(4, func() {
+		results, errs := parallelMapAll([]int{0, 1, 2, 3, 4}, func(item int) (int, error) {
+			if item%2 == 1 {
+				return 0, errOdd
+			}
+			return item * 10, nil
+		})
+		if want := []int{0, 0, 20, 0, 40}; !slices.Equal(results, want) {
+			t.Errorf("results = %v, want %v", results, want)
+		}
+		if want := []error{nil, errOdd, nil, errOdd, nil}; !slices.Equal(errs, want) {
+			t.Errorf("errs = %v, want %v", errs, want)
+		}
+	})
+}
+
+// newBenchmarkListing returns a listing of n files and n directories, with metadata encrypted like the API returns it.
+func newBenchmarkListing(b *testing.B, masterKey []byte, n int) *client.DirectoryContent {
+	content := &client.DirectoryContent{}
+	for i := 0; i < n; i++ {
+		metadata, err := json.Marshal(fileMetadata{
+			Name:         fmt.Sprintf("file%05d.txt", i),
+			Size:         i,
+			MimeType:     "text/plain",
+			Key:          crypto.GenerateRandomString(32),
+			LastModified: 1700000000,
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+		metadataEncrypted, err := crypto.EncryptMetadata(string(metadata), masterKey)
+		if err != nil {
+			b.Fatal(err)
+		}
+		content.Uploads = append(content.Uploads, client.DirectoryContentUpload{UUID: fmt.Sprint(i), Metadata: metadataEncrypted})
+
+		nameEncrypted, err := crypto.EncryptMetadata(fmt.Sprintf(`{"name":"directory%05d"}`, i), masterKey)
+		if err != nil {
+			b.Fatal(err)
+		}
+		content.Folders = append(content.Folders, client.DirectoryContentFolder{UUID: fmt.Sprint(i), Name: nameEncrypted})
+	}
+	return content
+}
+
+// BenchmarkDecryptListing compares decrypting the items of a listing one by one with decrypting them in parallel.
+func BenchmarkDecryptListing(b *testing.B) {
+	// the items are encrypted with the older of two master keys, so that both are tried
+	oldKey := []byte("0123456789abcdef0123456789abcdef")
+	filen := &Filen{MasterKeys: [][]byte{oldKey, []byte("fedcba9876543210fedcba9876543210")}}
+
+	for _, n := range []int{100, 1000} {
+		content := newBenchmarkListing(b, oldKey, n)
+		b.Run(fmt.Sprintf("sequential/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, upload := range content.Uploads {
+					if _, err := filen.newFile(upload); err != nil {
+						b.Fatal(err)
+					}
+				}
+				for _, folder := range content.Folders {
+					if _, err := filen.newDirectory(folder); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("parallel/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, errs := parallelMapAll(content.Uploads, filen.newFile)
+				if err := errors.Join(errs...); err != nil {
+					b.Fatal(err)
+				}
+				_, errs = parallelMapAll(content.Folders, filen.newDirectory)
+				if err := errors.Join(errs...); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
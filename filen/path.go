@@ -0,0 +1,66 @@
+package filen
+
+import (
+	"slices"
+	"strings"
+)
+
+// A CloudPath is a cleaned, absolute path on the cloud drive. The zero value is the root directory.
+type CloudPath struct {
+	segments []string
+}
+
+// ParsePath parses a slash-separated path on the cloud drive. It is cleaned like [path.Clean]:
+// leading, trailing and repeated slashes as well as "." segments are ignored, and ".." removes the preceding segment
+// (".." at the root directory stays at the root directory). Whether the path starts with a slash makes no difference.
+func ParsePath(path string) CloudPath {
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		switch segment {
+		case "", ".":
+		case "..":
+			if len(segments) > 0 {
+				segments = segments[:len(segments)-1]
+			}
+		default:
+			segments = append(segments, segment)
+		}
+	}
+	return CloudPath{segments}
+}
+
+// String returns the path in its cleaned form, e.g. "/a/b", or "/" for the root directory.
+func (path CloudPath) String() string {
+	return "/" + strings.Join(path.segments, "/")
+}
+
+// Segments returns the names along the path, or an empty slice for the root directory.
+func (path CloudPath) Segments() []string {
+	return slices.Clone(path.segments)
+}
+
+// IsRoot reports whether the path denotes the root directory.
+func (path CloudPath) IsRoot() bool {
+	return len(path.segments) == 0
+}
+
+// Base returns the last segment of the path, or an empty string for the root directory.
+func (path CloudPath) Base() string {
+	if path.IsRoot() {
+		return ""
+	}
+	return path.segments[len(path.segments)-1]
+}
+
+// Parent returns the path of the parent directory. The parent of the root directory is the root directory.
+func (path CloudPath) Parent() CloudPath {
+	if path.IsRoot() {
+		return path
+	}
+	return CloudPath{slices.Clip(path.segments[:len(path.segments)-1])}
+}
+
+// Join appends the elements (each of which may contain several segments) to the path and cleans the result.
+func (path CloudPath) Join(elements ...string) CloudPath {
+	return ParsePath(path.String() + "/" + strings.Join(elements, "/"))
+}
@@ -0,0 +1,105 @@
+package filen
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		segments []string
+		want     string
+	}{
+		{"", nil, "/"},
+		{"/", nil, "/"},
+		{"//", nil, "/"},
+		{"a", []string{"a"}, "/a"},
+		{"/a/b", []string{"a", "b"}, "/a/b"},
+		{"a/b/", []string{"a", "b"}, "/a/b"},
+		{"//a//b//", []string{"a", "b"}, "/a/b"},
+		{"/a/./b/.", []string{"a", "b"}, "/a/b"},
+		{"/a/../b", []string{"b"}, "/b"},
+		{"/a/b/../..", nil, "/"},
+		{"/../a", []string{"a"}, "/a"},
+		{"..", nil, "/"},
+		{"/a/...", []string{"a", "..."}, "/a/..."},
+		{"/ a /b ", []string{" a ", "b "}, "/ a /b "},
+		{"/a\\b", []string{"a\\b"}, "/a\\b"},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			path := ParsePath(test.path)
+			if segments := path.Segments(); !slices.Equal(segments, test.segments) {
+				t.Errorf("Segments() = %q, want %q", segments, test.segments)
+			}
+			if got := path.String(); got != test.want {
+				t.Errorf("String() = %q, want %q", got, test.want)
+			}
+			if path.IsRoot() != (len(test.segments) == 0) {
+				t.Errorf("IsRoot() = %v", path.IsRoot())
+			}
+			if reparsed := ParsePath(path.String()); reparsed.String() != path.String() {
+				t.Errorf("parsing String() again yields %q", reparsed.String())
+			}
+		})
+	}
+}
+
+func TestCloudPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		base   string
+		parent string
+	}{
+		{"/", "", "/"},
+		{"/a", "a", "/"},
+		{"/a/b/c.txt", "c.txt", "/a/b"},
+	}
+	for _, test := range tests {
+		path := ParsePath(test.path)
+		if base := path.Base(); base != test.base {
+			t.Errorf("ParsePath(%q).Base() = %q, want %q", test.path, base, test.base)
+		}
+		if parent := path.Parent().String(); parent != test.parent {
+			t.Errorf("ParsePath(%q).Parent() = %q, want %q", test.path, parent, test.parent)
+		}
+	}
+
+	var zero CloudPath
+	if !zero.IsRoot() || zero.String() != "/" {
+		t.Errorf("zero value is %q", zero.String())
+	}
+}
+
+func TestCloudPathJoin(t *testing.T) {
+	tests := []struct {
+		path     string
+		elements []string
+		want     string
+	}{
+		{"/", nil, "/"},
+		{"/", []string{"a"}, "/a"},
+		{"/a", []string{"b", "c"}, "/a/b/c"},
+		{"/a", []string{"b/c", "d"}, "/a/b/c/d"},
+		{"/a", []string{"/b/"}, "/a/b"},
+		{"/a/b", []string{".."}, "/a"},
+		{"/a", []string{"../../x"}, "/x"},
+	}
+	for _, test := range tests {
+		if got := ParsePath(test.path).Join(test.elements...).String(); got != test.want {
+			t.Errorf("ParsePath(%q).Join(%q) = %q, want %q", test.path, test.elements, got, test.want)
+		}
+	}
+}
+
+func TestCloudPathImmutable(t *testing.T) {
+	path := ParsePath("/a/b")
+	segments := path.Segments()
+	segments[0] = "x"
+	parent := path.Parent()
+	_ = append(parent.segments, "y") // must not overwrite b
+	if got := path.String(); got != "/a/b" {
+		t.Errorf("path changed to %q", got)
+	}
+}
@@ -0,0 +1,85 @@
+package filen
+
+import (
+	"sync"
+	"time"
+)
+
+// TransferProgress is a snapshot of the state of an upload or download.
+type TransferProgress struct {
+	BytesCompleted  int64         // how many bytes of file content have been transferred
+	BytesTotal      int64         // the total size of the file content in bytes, or -1 if not known yet
+	ChunksCompleted int           // how many chunks have been transferred
+	ChunksTotal     int           // the total number of chunks, or -1 if not known yet
+	Elapsed         time.Duration // time since the transfer was started
+	Throughput      float64       // the average transfer rate in bytes per second
+	ETA             time.Duration // the estimated remaining time, or -1 if it cannot be estimated yet
+}
+
+// A ProgressHandler is called with a [TransferProgress] every time a chunk has been transferred.
+// Calls for the same transfer never happen concurrently, so the handler doesn't need to synchronize,
+// but it should return quickly, as it blocks the transfer worker that invoked it.
+type ProgressHandler func(progress TransferProgress)
+
+// progressTracker accumulates progress from concurrent transfer workers and reports it to a ProgressHandler.
+type progressTracker struct {
+	handler     ProgressHandler
+	mu          sync.Mutex
+	started     time.Time
+	bytes       int64
+	bytesTotal  int64
+	chunks      int
+	chunksTotal int
+}
+
+func newProgressTracker(handler ProgressHandler, bytesTotal int64, chunksTotal int) *progressTracker {
+	return &progressTracker{
+		handler:     handler,
+		started:     time.Now(),
+		bytesTotal:  bytesTotal,
+		chunksTotal: chunksTotal,
+	}
+}
+
+// setTotal sets the total size once it is known (e.g. when an upload has read its input to the end).
+func (tracker *progressTracker) setTotal(bytesTotal int64, chunksTotal int) {
+	if tracker == nil {
+		return
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.bytesTotal = bytesTotal
+	tracker.chunksTotal = chunksTotal
+}
+
+// chunkCompleted records a transferred chunk of the given (plaintext) size and notifies the handler.
+func (tracker *progressTracker) chunkCompleted(size int) {
+	if tracker == nil || tracker.handler == nil {
+		return
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	tracker.bytes += int64(size)
+	tracker.chunks++
+	tracker.handler(tracker.snapshot())
+}
+
+func (tracker *progressTracker) snapshot() TransferProgress {
+	elapsed := time.Since(tracker.started)
+	progress := TransferProgress{
+		BytesCompleted:  tracker.bytes,
+		BytesTotal:      tracker.bytesTotal,
+		ChunksCompleted: tracker.chunks,
+		ChunksTotal:     tracker.chunksTotal,
+		Elapsed:         elapsed,
+		ETA:             -1,
+	}
+	if elapsed > 0 {
+		progress.Throughput = float64(tracker.bytes) / elapsed.Seconds()
+	}
+	if progress.Throughput > 0 && tracker.bytesTotal >= 0 {
+		remaining := float64(tracker.bytesTotal - tracker.bytes)
+		progress.ETA = time.Duration(remaining / progress.Throughput * float64(time.Second))
+	}
+	return progress
+}
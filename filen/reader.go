@@ -0,0 +1,113 @@
+package filen
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// A FileReader reads the content of a cloud file, downloading and decrypting chunks as they are needed.
+// It implements [io.Reader], [io.ReaderAt] and [io.Seeker]. ReadAt may be called concurrently,
+// while Read and Seek share an offset and must not be.
+type FileReader struct {
+	filen  *Filen
+	file   *File
+	offset int64 // the offset for Read and Seek
+
+	mu          sync.Mutex // protects the cached chunk
+	cachedChunk int
+	cachedData  []byte
+}
+
+// NewFileReader creates a FileReader for a cloud file.
+func (filen *Filen) NewFileReader(file *File) *FileReader {
+	return &FileReader{
+		filen:       filen,
+		file:        file,
+		cachedChunk: -1,
+	}
+}
+
+// Size returns the size of the file in bytes.
+func (reader *FileReader) Size() int64 {
+	return reader.file.Size
+}
+
+// ReadAt reads len(p) bytes starting at offset off, implementing [io.ReaderAt].
+func (reader *FileReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	n := 0
+	for n < len(p) && off < reader.file.Size {
+		chunk := int(off / ChunkSize)
+		chunkData, err := reader.chunk(chunk)
+		if err != nil {
+			return n, err
+		}
+		chunkOffset := int(off - int64(chunk)*ChunkSize)
+		if chunkOffset >= len(chunkData) {
+			return n, io.ErrUnexpectedEOF
+		}
+		copied := copy(p[n:], chunkData[chunkOffset:])
+		n += copied
+		off += int64(copied)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// chunk returns the decrypted data of a chunk, keeping the most recently used one around
+// so that sequential reads with small buffers don't download a chunk more than once.
+func (reader *FileReader) chunk(chunk int) ([]byte, error) {
+	reader.mu.Lock()
+	if reader.cachedChunk == chunk {
+		defer reader.mu.Unlock()
+		return reader.cachedData, nil
+	}
+	reader.mu.Unlock()
+
+	chunkData, err := reader.filen.downloadChunk(reader.file, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	reader.mu.Lock()
+	defer reader.mu.Unlock()
+	reader.cachedChunk = chunk
+	reader.cachedData = chunkData
+	return chunkData, nil
+}
+
+// Read implements [io.Reader].
+func (reader *FileReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := reader.ReadAt(p, reader.offset)
+	reader.offset += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// Seek implements [io.Seeker].
+func (reader *FileReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += reader.offset
+	case io.SeekEnd:
+		offset += reader.file.Size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	reader.offset = offset
+	return offset, nil
+}
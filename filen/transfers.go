@@ -1,43 +1,204 @@
 package filen
 
 import (
-	"encoding/hex"
-	"encoding/json"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
 	"github.com/FilenCloudDienste/filen-sdk-go/filen/crypto"
 	"github.com/google/uuid"
 	"io"
 	"math"
+	"mime"
 	"os"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 )
 
 const (
-	maxConcurrentDownloads = 16
-	maxConcurrentWriters   = 16
-	chunkSize              = 1048576
+	defaultMaxConcurrentDownloads = 16
+	defaultMaxConcurrentWriters   = 16
+	defaultMaxConcurrentUploads   = 16
+	defaultMaxConcurrentTransfers = 64
 )
 
+// ErrEmptyFile is returned when uploading empty content, which the API doesn't support.
+var ErrEmptyFile = errors.New("empty files are not supported")
+
+// ChunkSize is the size of the chunks file content is split into (except for the last chunk, which may be smaller).
+const ChunkSize = 1048576
+
+// ConcurrencyLimits configures how many chunk operations may run at the same time.
+// Zero values fall back to the defaults.
+type ConcurrencyLimits struct {
+	Downloads int // the maximum number of concurrent chunk downloads within a single download (default 16)
+	Writers   int // the maximum number of concurrent chunk handler calls within a single download (default 16)
+	Uploads   int // the maximum number of concurrent chunk uploads within a single upload (default 16)
+	Total     int // the maximum number of in-flight chunk requests across all transfers of the Filen instance (default 64)
+}
+
+// SetConcurrencyLimits configures the concurrency of transfers.
+// The per-transfer limits (Downloads, Writers and Uploads) apply to transfers started after the call,
+// while Total applies to all transfers immediately, including running ones: when it is lowered,
+// no new chunk requests are started until the number of in-flight requests has dropped below the new limit.
+func (filen *Filen) SetConcurrencyLimits(limits ConcurrencyLimits) {
+	if limits.Downloads <= 0 {
+		limits.Downloads = defaultMaxConcurrentDownloads
+	}
+	if limits.Writers <= 0 {
+		limits.Writers = defaultMaxConcurrentWriters
+	}
+	if limits.Uploads <= 0 {
+		limits.Uploads = defaultMaxConcurrentUploads
+	}
+	if limits.Total <= 0 {
+		limits.Total = defaultMaxConcurrentTransfers
+	}
+
+	filen.limitsMu.Lock()
+	defer filen.limitsMu.Unlock()
+	filen.limits = limits
+	filen.transfers.setLimit(limits.Total)
+}
+
+// ConcurrencyLimits returns the concurrency limits currently applied to new transfers.
+func (filen *Filen) ConcurrencyLimits() ConcurrencyLimits {
+	return filen.transferLimits()
+}
+
+// transferLimits returns the current limits, applying the defaults if none have been set.
+func (filen *Filen) transferLimits() ConcurrencyLimits {
+	filen.limitsMu.Lock()
+	initialized := filen.limits.Total != 0
+	filen.limitsMu.Unlock()
+	if !initialized {
+		filen.SetConcurrencyLimits(ConcurrencyLimits{})
+	}
+
+	filen.limitsMu.Lock()
+	defer filen.limitsMu.Unlock()
+	return filen.limits
+}
+
+// transferLimiter limits the number of in-flight chunk requests across all transfers of a Filen instance.
+// Unlike a channel used as semaphore, its limit can be changed while requests are in flight.
+// The zero value allows defaultMaxConcurrentTransfers requests.
+type transferLimiter struct {
+	mu       sync.Mutex
+	released *sync.Cond // signalled when a request has completed or the limit has been raised
+	limit    int
+	inFlight int
+}
+
+// acquire blocks until another request may be started.
+func (limiter *transferLimiter) acquire() {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if limiter.released == nil {
+		limiter.released = sync.NewCond(&limiter.mu)
+	}
+	for limiter.inFlight >= limiter.limitLocked() {
+		limiter.released.Wait()
+	}
+	limiter.inFlight++
+}
+
+// release marks a request started after acquire as completed.
+func (limiter *transferLimiter) release() {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.inFlight--
+	if limiter.released != nil {
+		limiter.released.Signal()
+	}
+}
+
+func (limiter *transferLimiter) setLimit(limit int) {
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	limiter.limit = limit
+	if limiter.released != nil {
+		limiter.released.Broadcast()
+	}
+}
+
+func (limiter *transferLimiter) limitLocked() int {
+	if limiter.limit <= 0 {
+		return defaultMaxConcurrentTransfers
+	}
+	return limiter.limit
+}
+
+// A TransferOption configures an individual upload or download.
+type TransferOption func(options *transferOptions)
+
+type transferOptions struct {
+	ctx          context.Context
+	progress     ProgressHandler
+	mimeType     string    // uploads only
+	lastModified time.Time // uploads only
+
+	conflictPolicy ConflictPolicy // uploads only
+}
+
+func newTransferOptions(opts []TransferOption) *transferOptions {
+	options := &transferOptions{ctx: context.Background()}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithContext makes the transfer stop with the context's error as soon as the context is done.
+func WithContext(ctx context.Context) TransferOption {
+	return func(options *transferOptions) {
+		options.ctx = ctx
+	}
+}
+
+// WithProgress registers a [ProgressHandler] that is notified whenever a chunk has been transferred.
+func WithProgress(handler ProgressHandler) TransferOption {
+	return func(options *transferOptions) {
+		options.progress = handler
+	}
+}
+
+// WithMimeType sets the MIME type of an uploaded file.
+// By default, it is derived from the file name's extension.
+func WithMimeType(mimeType string) TransferOption {
+	return func(options *transferOptions) {
+		options.mimeType = mimeType
+	}
+}
+
+// WithLastModified sets the last modification time of an uploaded file.
+// By default, the time of the upload is used.
+func WithLastModified(lastModified time.Time) TransferOption {
+	return func(options *transferOptions) {
+		options.lastModified = lastModified
+	}
+}
+
 // DownloadFileToDisk downloads a file from the cloud drive into a local destination on disk.
-func (filen *Filen) DownloadFileToDisk(file *File, destination *os.File) error {
+func (filen *Filen) DownloadFileToDisk(file *File, destination *os.File, opts ...TransferOption) error {
 	err := filen.DownloadFile(file, func(chunk int, data []byte) error {
-		_, err := destination.WriteAt(data, int64(chunk*chunkSize))
+		_, err := destination.WriteAt(data, int64(chunk*ChunkSize))
 		return err
-	})
+	}, opts...)
 	return err
 }
 
 // DownloadFileInMemory downloads a file from the cloud drive and stores its bytes in memory.
-func (filen *Filen) DownloadFileInMemory(file *File) ([]byte, error) {
+func (filen *Filen) DownloadFileInMemory(file *File, opts ...TransferOption) ([]byte, error) {
 	fileData := make([]byte, file.Size)
 	err := filen.DownloadFile(file, func(chunk int, data []byte) error {
-		chunkStart := chunk * chunkSize
-		chunkEnd := int(math.Min(float64(chunk+1)*float64(chunkSize), float64(file.Size)))
+		chunkStart := chunk * ChunkSize
+		chunkEnd := int(math.Min(float64(chunk+1)*float64(ChunkSize), float64(file.Size)))
 		copy(fileData[chunkStart:chunkEnd], data)
 		return nil
-	})
+	}, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -45,176 +206,333 @@ func (filen *Filen) DownloadFileInMemory(file *File) ([]byte, error) {
 }
 
 // DownloadFile downloads a file from the cloud drive and calls the chunkHandler for every received chunk.
-func (filen *Filen) DownloadFile(file *File, chunkHandler func(chunk int, data []byte) error) error {
-	downloadSem := make(chan int, maxConcurrentDownloads)
-	writeSem := make(chan int, maxConcurrentWriters)
+func (filen *Filen) DownloadFile(file *File, chunkHandler func(chunk int, data []byte) error, opts ...TransferOption) error {
+	options := newTransferOptions(opts)
+	progress := newProgressTracker(options.progress, file.Size, file.Chunks)
+
+	limits := filen.transferLimits()
+	downloadSem := make(chan int, limits.Downloads)
+	writeSem := make(chan int, limits.Writers)
 	cFinished := make(chan int)
 	errs := make(chan error)
+	cancelled := make(chan struct{}) // closed when the download fails, so that remaining workers stop
+	defer close(cancelled)
 
 	// download chunks, decrypt and write to disk concurrently
 	for chunk := 0; chunk < file.Chunks; chunk++ {
 		go func() {
-			downloadSem <- 1
-			defer func() { <-downloadSem }()
-
-			encryptedChunkData, err := filen.client.DownloadFileChunk(file.UUID, file.Region, file.Bucket, chunk)
-			if err != nil {
-				errs <- err
+			select {
+			case downloadSem <- 1:
+			case <-cancelled:
 				return
 			}
-			chunkData, err := crypto.DecryptData(encryptedChunkData, file.EncryptionKey)
+			defer func() { <-downloadSem }()
+
+			chunkData, err := filen.downloadChunk(file, chunk)
 			if err != nil {
-				errs <- err
+				sendOrCancel(errs, err, cancelled)
 				return
 			}
 
 			go func() {
-				writeSem <- 1
+				select {
+				case writeSem <- 1:
+				case <-cancelled:
+					return
+				}
 				defer func() { <-writeSem }()
 
 				err = chunkHandler(chunk, chunkData)
 				if err != nil {
-					errs <- err
+					sendOrCancel(errs, err, cancelled)
 					return
 				}
+				progress.chunkCompleted(len(chunkData))
 
-				cFinished <- 1
+				sendOrCancel(cFinished, 1, cancelled)
 			}()
 		}()
 	}
 
 	// wait for all to finish, or return error
 	finished := 0
-	for {
+	for finished < file.Chunks {
 		select {
 		case <-cFinished:
 			finished++
-			if finished == file.Chunks {
-				return nil
-			}
 		case err := <-errs:
 			return err
+		case <-options.ctx.Done():
+			return options.ctx.Err()
 		}
 	}
+	return nil
 }
 
-const maxConcurrentUploads = 16
+// downloadChunk downloads and decrypts a single chunk of a file.
+func (filen *Filen) downloadChunk(file *File, chunk int) ([]byte, error) {
+	filen.transfers.acquire()
+	encryptedChunkData, err := filen.client.DownloadFileChunk(file.UUID, file.Region, file.Bucket, chunk)
+	filen.transfers.release()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.DecryptData(encryptedChunkData, file.EncryptionKey)
+}
 
 // UploadFile uploads data to a cloud file (specified by its name and its parent directory's UUID).
-func (filen *Filen) UploadFile(fileName string, parentUUID string, data io.Reader) (*File, error) {
-	uploaderSem := make(chan int, maxConcurrentUploads)
+// If data is an [io.ReaderAt] with a known size (like an *os.File), chunks are read in parallel by offset;
+// otherwise, data is read sequentially. Empty data cannot be uploaded ([ErrEmptyFile]).
+//
+// If the upload fails or is cancelled after chunks have already been stored, UploadFile returns without waiting
+// for these chunks to be purged, which happens in the background. Until then, and should that fail,
+// the upload is tracked as an [IncompleteUpload] so that it can be purged later.
+//
+// See [ConflictPolicy] for how existing files with the same name are handled. If a file was uploaded
+// but the file it replaces could not be removed, both the new file and an error are returned.
+func (filen *Filen) UploadFile(fileName string, parentUUID string, data io.Reader, opts ...TransferOption) (*File, error) {
+	options := newTransferOptions(opts)
+	progress := newProgressTracker(options.progress, -1, -1)
+	fileName, err := filen.newItemName(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	// check for an existing file with the same name
+	var existing *File
+	if options.conflictPolicy != ConflictNoCheck {
+		fileName, existing, err = filen.resolveUploadConflict(fileName, parentUUID, options.conflictPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && options.conflictPolicy == ConflictSkip {
+			return existing, nil
+		}
+	}
+
+	limits := filen.transferLimits()
+	uploaderSem := make(chan int, limits.Uploads)
 	uploadFinished := make(chan int)
 	errs := make(chan error)
+	cancelled := make(chan struct{}) // closed when the upload fails, so that remaining workers stop
+	var uploaders sync.WaitGroup
 
 	var region, bucket string
+	var regionMu sync.Mutex
 
 	// uploader
 	fileUUID := uuid.New().String()
 	key := []byte(crypto.GenerateRandomString(32))
 	uploadKey := crypto.GenerateRandomString(32)
-	uploader := func(chunkData []byte, chunkIdx int) {
-		uploaderSem <- 1
+	upload := &IncompleteUpload{
+		UUID:          fileUUID,
+		Name:          fileName,
+		ParentUUID:    parentUUID,
+		UploadKey:     uploadKey,
+		EncryptionKey: key,
+		Started:       time.Now(),
+	}
+	uploader := func(chunkIdx int, readChunk func() ([]byte, error)) {
+		defer uploaders.Done()
+		select {
+		case uploaderSem <- 1:
+		case <-cancelled:
+			return
+		}
 		defer func() { <-uploaderSem }()
 
+		chunkData, err := readChunk()
+		if err != nil {
+			sendOrCancel(errs, err, cancelled)
+			return
+		}
+
 		// encrypt data
-		chunkData, err := crypto.EncryptData(chunkData, key)
+		encryptedChunkData, err := crypto.EncryptData(chunkData, key)
 		if err != nil {
-			errs <- err
+			sendOrCancel(errs, err, cancelled)
+			return
 		}
 
 		// upload chunk
-		uploadRegion, uploadBucket, err := filen.client.UploadFileChunk(fileUUID, chunkIdx, parentUUID, uploadKey, chunkData)
+		filen.transfers.acquire()
+		uploadRegion, uploadBucket, err := filen.client.UploadFileChunk(fileUUID, chunkIdx, parentUUID, uploadKey, encryptedChunkData)
+		filen.transfers.release()
 		if err != nil {
-			errs <- err
+			sendOrCancel(errs, err, cancelled)
+			return
 		}
+		regionMu.Lock()
 		region = uploadRegion
 		bucket = uploadBucket
+		upload.UploadedChunks = append(upload.UploadedChunks, chunkIdx)
+		upload.UploadedBytes += int64(len(chunkData))
+		regionMu.Unlock()
+		progress.chunkCompleted(len(chunkData))
 
-		uploadFinished <- 1
-	}
-
-	// read chunks
-	b := make([]byte, chunkSize)
-	chunk := make([]byte, 0)
-	chunks := 0
-	totalBytes := 0
-	for {
-		n, err := data.Read(b)
-		totalBytes += n
-		chunk = append(chunk, b[:n]...)
-		if len(chunk) >= chunkSize || (err == io.EOF && len(chunk) > 0) {
-			chunkData := chunk
-			if len(chunk) > chunkSize {
-				chunkData = chunk[:chunkSize]
-			}
-			chunk = chunk[len(chunkData):]
+		sendOrCancel(uploadFinished, 1, cancelled)
+	}
 
-			go uploader(chunkData, chunks)
-			chunks++
+	// abort stops all uploaders and purges the chunks that have been stored in the background
+	abort := func(err error) (*File, error) {
+		close(cancelled)
+		filen.abortUpload(upload, &uploaders)
+		return nil, err
+	}
+
+	var chunks, totalBytes int
+	if readerAt, offset, size, ok := sizedReaderAt(data); ok {
+		// read chunks in parallel by offset
+		totalBytes = int(size - offset)
+		if totalBytes == 0 {
+			return nil, ErrEmptyFile
 		}
-		if err == io.EOF {
-			if totalBytes == 0 {
-				return nil, errors.New("empty uploads are not supported")
-			}
-			break
+		chunks = (totalBytes + ChunkSize - 1) / ChunkSize
+		progress.setTotal(int64(totalBytes), chunks)
+		for chunkIdx := 0; chunkIdx < chunks; chunkIdx++ {
+			chunkOffset := offset + int64(chunkIdx)*ChunkSize
+			uploaders.Add(1)
+			go uploader(chunkIdx, func() ([]byte, error) {
+				chunkData := make([]byte, min(ChunkSize, size-chunkOffset))
+				n, err := readerAt.ReadAt(chunkData, chunkOffset)
+				if n == len(chunkData) {
+					return chunkData, nil
+				}
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return nil, err
+			})
 		}
-		if err != nil {
-			return nil, err
+	} else {
+		// read chunks sequentially
+		b := make([]byte, ChunkSize)
+		chunk := make([]byte, 0)
+		for {
+			if err := options.ctx.Err(); err != nil {
+				return abort(err)
+			}
+			n, err := data.Read(b)
+			totalBytes += n
+			chunk = append(chunk, b[:n]...)
+			if len(chunk) >= ChunkSize || (err == io.EOF && len(chunk) > 0) {
+				chunkData := chunk
+				if len(chunk) > ChunkSize {
+					chunkData = chunk[:ChunkSize]
+				}
+				chunk = chunk[len(chunkData):]
+
+				uploaders.Add(1)
+				go uploader(chunks, func() ([]byte, error) { return chunkData, nil })
+				chunks++
+			}
+			if err == io.EOF {
+				if totalBytes == 0 {
+					return nil, ErrEmptyFile
+				}
+				progress.setTotal(int64(totalBytes), chunks)
+				break
+			}
+			if err != nil {
+				return abort(err)
+			}
 		}
 	}
 
 	// wait for all to finish, or return error
 	uploadsFinished := 0
-	if chunks != 0 {
-	WaitForAll:
-		for {
-			select {
-			case <-uploadFinished:
-				uploadsFinished++
-				if uploadsFinished == chunks {
-					break WaitForAll
-				}
-			case err := <-errs:
-				return nil, err
-			}
+	for uploadsFinished < chunks {
+		select {
+		case <-uploadFinished:
+			uploadsFinished++
+		case err := <-errs:
+			return abort(err)
+		case <-options.ctx.Done():
+			return abort(options.ctx.Err())
 		}
 	}
 
+	// mark upload as done
+	created := time.Now()
+	lastModified := options.lastModified
+	if lastModified.IsZero() {
+		lastModified = created
+	}
+	mimeType := options.mimeType
+	if mimeType == "" {
+		mimeType = mimeTypeByName(fileName)
+	}
+	response, err := filen.uploadDone(fileUUID, uploadKey, chunks, fileMetadata{
+		Name:         fileName,
+		Size:         totalBytes,
+		MimeType:     mimeType,
+		Key:          string(key),
+		LastModified: int(lastModified.Unix()),
+		Created:      int(created.Unix()),
+	})
+	if err != nil {
+		return abort(err)
+	}
+	filen.cache.invalidateDirectory(parentUUID)
+
+	file := &File{
+		UUID:          fileUUID,
+		Name:          fileName,
+		Size:          int64(totalBytes),
+		MimeType:      mimeType,
+		EncryptionKey: key,
+		Created:       created,
+		LastModified:  lastModified,
+		ParentUUID:    parentUUID,
+		Favorited:     false,
+		Region:        region,
+		Bucket:        bucket,
+		Chunks:        response.Chunks,
+	}
+	if existing != nil && options.conflictPolicy == ConflictReplace {
+		err = filen.replaceFile(existing)
+		if err != nil {
+			return file, fmt.Errorf("remove replaced file %s: %w", existing.UUID, err)
+		}
+	}
+	return file, nil
+}
+
+// mimeTypeByName guesses the MIME type of a file from its extension.
+func mimeTypeByName(fileName string) string {
+	mimeType := mime.TypeByExtension(filepath.Ext(fileName))
+	if mimeType == "" {
+		return "application/octet-stream"
+	}
+	return mimeType
+}
+
+// uploadDone encrypts the file metadata and marks an upload whose chunks have all been stored as done.
+func (filen *Filen) uploadDone(fileUUID string, uploadKey string, chunks int, metadata fileMetadata) (*client.UploadDoneResponse, error) {
+	key := []byte(metadata.Key)
+
 	// encrypt info about file
-	nameEncrypted, err := crypto.EncryptMetadata(fileName, key)
+	nameEncrypted, err := crypto.EncryptMetadata(metadata.Name, key)
 	if err != nil {
 		return nil, err
 	}
-	nameHashed := hex.EncodeToString(crypto.RunSHA521([]byte(fileName)))
-	mimeType, err := crypto.EncryptMetadata("text/plain", key)
+	nameHashed := hashName(metadata.Name)
+	mimeType, err := crypto.EncryptMetadata(metadata.MimeType, key)
 	if err != nil {
 		return nil, err
 	}
-	sizeEncrypted, err := crypto.EncryptMetadata(strconv.Itoa(totalBytes), key)
+	sizeEncrypted, err := crypto.EncryptMetadata(strconv.Itoa(metadata.Size), key)
 	if err != nil {
 		return nil, err
 	}
 
 	// encrypt file metadata
-	metadata := struct {
-		Name         string `json:"name"`
-		Size         int    `json:"size"`
-		MimeType     string `json:"mime"`
-		Key          string `json:"key"`
-		LastModified int    `json:"lastModified"`
-		Created      int    `json:"created"`
-	}{fileName, totalBytes, "text/plain", string(key), int(time.Now().Unix()), int(time.Now().Unix())}
-	metadataStr, err := json.Marshal(metadata)
-	if err != nil {
-		return nil, err
-	}
-	metadataEncrypted, err := crypto.EncryptMetadata(string(metadataStr), filen.CurrentMasterKey())
+	metadataEncrypted, err := filen.encryptFileMetadata(metadata)
 	if err != nil {
 		return nil, err
 	}
 
-	// mark upload as done
-	response, err := filen.client.UploadDone(client.UploadDoneRequest{
+	return filen.client.UploadDone(client.UploadDoneRequest{
 		UUID:       fileUUID,
 		Name:       nameEncrypted,
 		NameHashed: nameHashed,
@@ -226,22 +544,42 @@ func (filen *Filen) UploadFile(fileName string, parentUUID string, data io.Reade
 		Version:    2,
 		UploadKey:  uploadKey,
 	})
-	if err != nil {
-		return nil, err
+}
+
+// sendOrCancel sends value to a channel, unless the transfer has been cancelled.
+func sendOrCancel[T any](channel chan<- T, value T, cancelled <-chan struct{}) {
+	select {
+	case channel <- value:
+	case <-cancelled:
 	}
+}
 
-	return &File{
-		UUID:          fileUUID,
-		Name:          fileName,
-		Size:          int64(totalBytes),
-		MimeType:      "application/octet-stream", //TODO correct mime type
-		EncryptionKey: []byte(uploadKey),
-		Created:       time.Now(), //TODO really?
-		LastModified:  time.Now(),
-		ParentUUID:    parentUUID,
-		Favorited:     false,
-		Region:        region,
-		Bucket:        bucket,
-		Chunks:        response.Chunks,
-	}, nil
+// sizedReaderAt checks whether data can be read in parallel by offset, which is the case for
+// seekable sources like *os.File (for regular files), *bytes.Reader or *io.SectionReader.
+// It returns the current read offset and the total size, and leaves data positioned at its end
+// (as if it had been read sequentially).
+func sizedReaderAt(data io.Reader) (readerAt io.ReaderAt, offset int64, size int64, ok bool) {
+	readerAt, ok = data.(io.ReaderAt)
+	if !ok {
+		return nil, 0, 0, false
+	}
+	seeker, ok := data.(io.Seeker)
+	if !ok {
+		return nil, 0, 0, false
+	}
+	if file, isFile := data.(*os.File); isFile {
+		info, err := file.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return nil, 0, 0, false
+		}
+	}
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, 0, false
+	}
+	size, err = seeker.Seek(0, io.SeekEnd)
+	if err != nil || size < offset {
+		return nil, 0, 0, false
+	}
+	return readerAt, offset, size, true
 }
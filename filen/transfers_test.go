@@ -0,0 +1,158 @@
+package filen
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTransferLimiterSetLimitWhileInFlight(t *testing.T) {
+	limiter := &transferLimiter{}
+	limiter.setLimit(2)
+	limiter.acquire()
+	limiter.acquire()
+
+	// lowering the limit must hold back new requests until enough in-flight ones have completed
+	limiter.setLimit(1)
+	acquired := make(chan struct{})
+	go func() {
+		limiter.acquire()
+		close(acquired)
+	}()
+
+	limiter.release()
+	select {
+	case <-acquired:
+		t.Fatal("acquired with 1 request in flight and a limit of 1")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	limiter.release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("not acquired after all requests have completed")
+	}
+
+	// raising the limit must wake up waiting requests
+	acquired = make(chan struct{})
+	go func() {
+		limiter.acquire()
+		close(acquired)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	limiter.setLimit(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("not acquired after the limit has been raised")
+	}
+}
+
+func TestTransferLimiterZeroValue(t *testing.T) {
+	limiter := &transferLimiter{}
+	for i := 0; i < defaultMaxConcurrentTransfers; i++ {
+		limiter.acquire()
+	}
+	if limiter.inFlight != defaultMaxConcurrentTransfers {
+		t.Fatalf("inFlight = %d, want %d", limiter.inFlight, defaultMaxConcurrentTransfers)
+	}
+}
+
+func TestDownloadEmptyFile(t *testing.T) {
+	filen := &Filen{}
+	done := make(chan error)
+	go func() {
+		done <- filen.DownloadFile(&File{UUID: "empty"}, func(chunk int, data []byte) error {
+			t.Errorf("chunk handler called for chunk %d of an empty file", chunk)
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("downloading an empty file doesn't return")
+	}
+}
+
+// blockingUploadTransport answers API and storage requests successfully, except that it holds back uploads of
+// chunk 1 until release is closed.
+type blockingUploadTransport struct {
+	blocked chan struct{} // closed when an upload of chunk 1 has been held back
+	release chan struct{}
+	once    sync.Once
+
+	mu    sync.Mutex
+	paths []string // the paths of the requests that were answered
+}
+
+func (transport *blockingUploadTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Path == "/v3/upload" && req.URL.Query().Get("index") == "1" {
+		transport.once.Do(func() { close(transport.blocked) })
+		<-transport.release
+	}
+	if req.Body != nil {
+		_, _ = io.Copy(io.Discard, req.Body)
+	}
+	transport.mu.Lock()
+	transport.paths = append(transport.paths, req.URL.Path)
+	transport.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(`{"status":true,"data":{"region":"r","bucket":"b","chunks":2,"size":2}}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestUploadFileCancelReturnsPromptly(t *testing.T) {
+	transport := &blockingUploadTransport{blocked: make(chan struct{}), release: make(chan struct{})}
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = transport
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	filen := &Filen{client: &client.Client{}, MasterKeys: [][]byte{[]byte("00000000000000000000000000000000")}}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := filen.UploadFile("file", "parent", bytes.NewReader(make([]byte, 2*ChunkSize)),
+			WithConflictPolicy(ConflictNoCheck), WithContext(ctx))
+		done <- err
+	}()
+
+	<-transport.blocked
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("err = %v, want %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelling doesn't return while a chunk upload is in flight")
+	}
+
+	// once the chunk upload has finished, the stored chunks are purged in the background
+	close(transport.release)
+	filen.purges.Wait()
+	if uploads := filen.IncompleteUploads(); len(uploads) != 0 {
+		t.Errorf("%d incomplete uploads left", len(uploads))
+	}
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	for _, path := range []string{"/v3/upload/done", "/v3/file/delete/permanent"} {
+		if !slices.Contains(transport.paths, path) {
+			t.Errorf("no request to %s for purging the upload, requests: %v", path, transport.paths)
+		}
+	}
+}
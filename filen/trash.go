@@ -0,0 +1,42 @@
+package filen
+
+// trashDirectoryUUID is the pseudo directory UUID by which the API lists the trash.
+const trashDirectoryUUID = "trash"
+
+// ListTrash fetches the files and directories that have been moved to trash.
+func (filen *Filen) ListTrash() ([]*File, []*Directory, error) {
+	directoryContent, err := filen.client.GetDirectoryContent(trashDirectoryUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filen.decryptDirectoryContent(directoryContent)
+}
+
+// RestoreFile restores a file from trash into its original directory.
+func (filen *Filen) RestoreFile(uuid string) error {
+	defer filen.cache.invalidateAll() // the parent directory is not known
+	return filen.client.RestoreFile(uuid)
+}
+
+// RestoreDirectory restores a directory from trash into its original parent directory.
+func (filen *Filen) RestoreDirectory(uuid string) error {
+	defer filen.cache.invalidateAll() // the parent directory is not known
+	return filen.client.RestoreDirectory(uuid)
+}
+
+// DeleteFilePermanently deletes a file irrecoverably.
+func (filen *Filen) DeleteFilePermanently(uuid string) error {
+	defer filen.cache.invalidateItem(uuid)
+	return filen.client.DeleteFilePermanently(uuid)
+}
+
+// DeleteDirectoryPermanently deletes a directory and all its content irrecoverably.
+func (filen *Filen) DeleteDirectoryPermanently(uuid string) error {
+	defer filen.cache.invalidateItem(uuid)
+	return filen.client.DeleteDirectoryPermanently(uuid)
+}
+
+// EmptyTrash permanently deletes all items in trash.
+func (filen *Filen) EmptyTrash() error {
+	return filen.client.EmptyTrash()
+}
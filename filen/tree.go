@@ -0,0 +1,148 @@
+package filen
+
+import (
+	"fmt"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/client"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/util"
+	"slices"
+	"strings"
+)
+
+// A DirectoryTree is a directory together with all files and directories it contains, recursively.
+type DirectoryTree struct {
+	*Directory
+	Path        string           // the path relative to the root of the tree ("" for the root itself)
+	Parent      *DirectoryTree   // the parent directory, or nil for the root of the tree
+	Directories []*DirectoryTree // the directories within this directory, sorted by name
+	Files       []*TreeFile      // the files within this directory, sorted by name
+}
+
+// A TreeFile is a file within a [DirectoryTree].
+type TreeFile struct {
+	*File
+	Path   string         // the path relative to the root of the tree
+	Parent *DirectoryTree // the directory that contains the file
+}
+
+// ReadDirectoryTree fetches a directory (specified by UUID) with its entire content in one request.
+// The directories in the tree don't carry colors or favorite marks, as these are not included in the response.
+func (filen *Filen) ReadDirectoryTree(uuid string) (*DirectoryTree, error) {
+	response, err := filen.client.GetDirectoryTree(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	// decrypt metadata in parallel
+	directories, err := parallelMap(response.Folders, func(folder client.DirectoryTreeFolder) (*DirectoryTree, error) {
+		name, err := filen.decryptDirectoryName(folder.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &DirectoryTree{Directory: &Directory{
+			UUID:       folder.UUID,
+			Name:       name,
+			ParentUUID: folder.Parent,
+			Created:    util.TimestampToTime(int64(folder.Timestamp)),
+		}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	files, err := parallelMap(response.Files, func(file client.DirectoryTreeFile) (*TreeFile, error) {
+		metadata, err := filen.decryptFileMetadata(file.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		return &TreeFile{File: &File{
+			UUID:          file.UUID,
+			Name:          metadata.Name,
+			Size:          int64(metadata.Size),
+			MimeType:      metadata.MimeType,
+			EncryptionKey: []byte(metadata.Key),
+			Created:       util.TimestampToTime(int64(file.Timestamp)),
+			LastModified:  util.TimestampToTime(int64(metadata.LastModified)),
+			ParentUUID:    file.Parent,
+			Region:        file.Region,
+			Bucket:        file.Bucket,
+			Chunks:        file.Chunks,
+		}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// link the tree
+	directoriesByUUID := make(map[string]*DirectoryTree, len(directories))
+	for _, directory := range directories {
+		directoriesByUUID[directory.UUID] = directory
+	}
+	root, ok := directoriesByUUID[uuid]
+	if !ok {
+		return nil, fmt.Errorf("directory %s is missing from its own tree", uuid)
+	}
+	for _, directory := range directories {
+		if directory == root {
+			continue
+		}
+		parent, ok := directoriesByUUID[directory.ParentUUID]
+		if !ok {
+			return nil, fmt.Errorf("parent %s of directory %s is missing from the tree", directory.ParentUUID, directory.UUID)
+		}
+		directory.Parent = parent
+		parent.Directories = append(parent.Directories, directory)
+	}
+	for _, file := range files {
+		parent, ok := directoriesByUUID[file.ParentUUID]
+		if !ok {
+			return nil, fmt.Errorf("parent %s of file %s is missing from the tree", file.ParentUUID, file.UUID)
+		}
+		file.Parent = parent
+		parent.Files = append(parent.Files, file)
+	}
+	root.computePaths("")
+
+	return root, nil
+}
+
+// computePaths sorts the content of the tree and sets the paths of all its items.
+func (tree *DirectoryTree) computePaths(path string) {
+	tree.Path = path
+	prefix := path
+	if prefix != "" {
+		prefix += "/"
+	}
+	slices.SortFunc(tree.Directories, func(a, b *DirectoryTree) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(tree.Files, func(a, b *TreeFile) int { return strings.Compare(a.Name, b.Name) })
+	for _, file := range tree.Files {
+		file.Path = prefix + file.Name
+	}
+	for _, directory := range tree.Directories {
+		directory.computePaths(prefix + directory.Name)
+	}
+}
+
+// Find finds an item within the tree by its path relative to the tree's root (either the File or the Directory will be returned).
+// Set requireDirectory to differentiate between files and directories with the same path (otherwise, the file will be found).
+// Returns nil for both if none was found.
+func (tree *DirectoryTree) Find(path string, requireDirectory bool) (*TreeFile, *DirectoryTree) {
+	segments := slices.DeleteFunc(strings.Split(path, "/"), func(segment string) bool { return segment == "" })
+	current := tree
+SegmentsLoop:
+	for segmentIdx, segment := range segments {
+		if segmentIdx == len(segments)-1 && !requireDirectory {
+			for _, file := range current.Files {
+				if file.Name == segment {
+					return file, nil
+				}
+			}
+		}
+		for _, directory := range current.Directories {
+			if directory.Name == segment {
+				current = directory
+				continue SegmentsLoop
+			}
+		}
+		return nil, nil
+	}
+	return nil, current
+}
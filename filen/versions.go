@@ -0,0 +1,56 @@
+package filen
+
+import (
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/util"
+	"slices"
+)
+
+// ListFileVersions fetches the stored versions of a file, newest first.
+// Each version is returned as a File whose Created time is when the version was stored,
+// and can be downloaded like any other file, e.g. using [Filen.DownloadFile].
+func (filen *Filen) ListFileVersions(file *File) ([]*File, error) {
+	fileVersions, err := filen.client.GetFileVersions(file.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]*File, 0)
+	for _, version := range fileVersions.Versions {
+		metadata, err := filen.decryptFileMetadata(version.Metadata)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, &File{
+			UUID:          version.UUID,
+			Name:          metadata.Name,
+			Size:          int64(metadata.Size),
+			MimeType:      metadata.MimeType,
+			EncryptionKey: []byte(metadata.Key),
+			Created:       util.TimestampToTime(int64(version.Timestamp)),
+			LastModified:  util.TimestampToTime(int64(metadata.LastModified)),
+			ParentUUID:    file.ParentUUID,
+			Favorited:     file.Favorited,
+			Region:        version.Region,
+			Bucket:        version.Bucket,
+			Chunks:        version.Chunks,
+		})
+	}
+	slices.SortStableFunc(versions, func(a, b *File) int {
+		return b.Created.Compare(a.Created)
+	})
+	return versions, nil
+}
+
+// RestoreFileVersion makes an older version (as returned by [Filen.ListFileVersions]) the current version of a file.
+// The previously current version is kept as a version. Returns the file as it is now current.
+func (filen *Filen) RestoreFileVersion(file *File, version *File) (*File, error) {
+	err := filen.client.RestoreFileVersion(version.UUID, file.UUID)
+	if err != nil {
+		return nil, err
+	}
+	filen.cache.invalidateDirectory(file.ParentUUID)
+	restored := *version
+	restored.ParentUUID = file.ParentUUID
+	restored.Favorited = file.Favorited
+	return &restored, nil
+}
@@ -0,0 +1,421 @@
+package vfs
+
+import (
+	"errors"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen/filenfs"
+	"github.com/google/uuid"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// Backend is the part of [filen.Filen] a [FilenFS] is built on. It can be substituted, e.g. for testing.
+type Backend interface {
+	filenfs.Backend
+	FindItemUUID(path string, requireDirectory bool) (string, error)
+	FileExists(parentUUID string, name string) (bool, string, error)
+	DirectoryExists(parentUUID string, name string) (bool, string, error)
+	CreateDirectory(parentUUID string, name string) (*filen.Directory, error)
+	FindDirectoryOrCreate(path string) (string, error)
+	TrashFile(uuid string) error
+	TrashDirectory(uuid string) error
+	RestoreFile(uuid string) error
+	RenameFile(file *filen.File, name string) (*filen.File, error)
+	RenameDirectory(directory *filen.Directory, name string) (*filen.Directory, error)
+	MoveFile(file *filen.File, parentUUID string) (*filen.File, error)
+	MoveDirectory(directory *filen.Directory, parentUUID string) (*filen.Directory, error)
+	UploadFile(fileName string, parentUUID string, data io.Reader, opts ...filen.TransferOption) (*filen.File, error)
+}
+
+var _ Backend = (*filen.Filen)(nil)
+
+// FilenFS is an [FS] on a Filen drive.
+//
+// Files are created by streaming the written content into [filen.Filen.UploadFile], which completes on Close.
+// Removed items are moved to trash. Since Filen doesn't support empty files,
+// closing a created file that nothing has been written to fails with [filen.ErrEmptyFile].
+type FilenFS struct {
+	backend Backend
+	root    string
+	ro      *filenfs.FS // used for reading
+}
+
+var _ FS = (*FilenFS)(nil)
+
+// NewFilenFS creates a FilenFS for the directory at the given path on a Filen drive ("" or "/" for the whole drive).
+// The backend is usually a [*filen.Filen].
+func NewFilenFS(backend Backend, root string) *FilenFS {
+	root = strings.Trim(root, "/")
+	return &FilenFS{backend, root, filenfs.New(backend, root)}
+}
+
+// cloudPath converts a name to a path on the drive.
+func (fsys *FilenFS) cloudPath(name string) string {
+	return path.Join("/", fsys.root, cleanName(name))
+}
+
+// find finds the item with the given name (either the File or the Directory will be returned).
+func (fsys *FilenFS) find(op string, name string, requireDirectory bool) (*filen.File, *filen.Directory, error) {
+	if cleanName(name) == "." {
+		uuid, err := fsys.backend.FindItemUUID(fsys.cloudPath(name), true)
+		if err != nil {
+			return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+		}
+		if uuid == "" {
+			return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+		}
+		return nil, &filen.Directory{UUID: uuid}, nil
+	}
+	file, directory, err := fsys.backend.FindItem(fsys.cloudPath(name), requireDirectory)
+	if err != nil {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+	if file == nil && directory == nil {
+		return nil, nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+	}
+	return file, directory, nil
+}
+
+// findParent finds the directory that contains the named item, and returns its UUID and the item's base name.
+func (fsys *FilenFS) findParent(op string, name string) (string, string, error) {
+	name = cleanName(name)
+	if name == "." {
+		return "", "", &fs.PathError{Op: op, Path: name, Err: errors.New("cannot use the root directory")}
+	}
+	_, parent, err := fsys.find(op, path.Dir(name), true)
+	if err != nil {
+		return "", "", err
+	}
+	return parent.UUID, path.Base(name), nil
+}
+
+func (fsys *FilenFS) Open(name string) (File, error) {
+	file, err := fsys.ro.Open(cleanName(name))
+	if err != nil {
+		return nil, err
+	}
+	return &readFile{file}, nil
+}
+
+func (fsys *FilenFS) Create(name string) (File, error) {
+	parentUUID, baseName, err := fsys.findParent("create", name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, writer := io.Pipe()
+	file := &writeFile{name: name, baseName: baseName, writer: writer, done: make(chan struct{})}
+	go func() {
+		defer close(file.done)
+		file.file, file.err = fsys.backend.UploadFile(baseName, parentUUID, reader, filen.WithConflictPolicy(filen.ConflictReplace))
+		if file.err != nil {
+			_ = reader.CloseWithError(file.err) // fail further writes
+		}
+	}()
+	return file, nil
+}
+
+func (fsys *FilenFS) Mkdir(name string) error {
+	parentUUID, baseName, err := fsys.findParent("mkdir", name)
+	if err != nil {
+		return err
+	}
+	exists, _, err := fsys.backend.DirectoryExists(parentUUID, baseName)
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	if exists {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: fs.ErrExist}
+	}
+	_, err = fsys.backend.CreateDirectory(parentUUID, baseName)
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (fsys *FilenFS) MkdirAll(name string) error {
+	_, err := fsys.backend.FindDirectoryOrCreate(fsys.cloudPath(name))
+	if err != nil {
+		return &fs.PathError{Op: "mkdir", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (fsys *FilenFS) Remove(name string) error {
+	if cleanName(name) == "." {
+		return &fs.PathError{Op: "remove", Path: name, Err: errors.New("cannot remove the root directory")}
+	}
+	file, directory, err := fsys.find("remove", name, false)
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		err = fsys.backend.TrashFile(file.UUID)
+	} else {
+		var files []*filen.File
+		var directories []*filen.Directory
+		files, directories, err = fsys.backend.ReadDirectory(directory.UUID)
+		if err == nil && len(files)+len(directories) > 0 {
+			err = errors.New("directory not empty")
+		}
+		if err == nil {
+			err = fsys.backend.TrashDirectory(directory.UUID)
+		}
+	}
+	if err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: err}
+	}
+	return nil
+}
+
+func (fsys *FilenFS) RemoveAll(name string) error {
+	if cleanName(name) == "." {
+		return &fs.PathError{Op: "removeall", Path: name, Err: errors.New("cannot remove the root directory")}
+	}
+	file, directory, err := fsys.find("removeall", name, false)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if file != nil {
+		err = fsys.backend.TrashFile(file.UUID)
+	} else {
+		err = fsys.backend.TrashDirectory(directory.UUID)
+	}
+	if err != nil {
+		return &fs.PathError{Op: "removeall", Path: name, Err: err}
+	}
+	return nil
+}
+
+// Rename moves and renames a file or directory. An existing file at the new name is replaced (moved to trash),
+// while an existing directory causes the rename to fail with [fs.ErrExist].
+//
+// Items are moved before they are renamed, so only the destination directory is checked for conflicts.
+// If the destination contains an item with the current name, the item is moved under a temporary name.
+// A replaced file is only moved to trash right before the final rename, and restored if that fails.
+// If any step after the item has been moved or renamed fails, the item is moved back under its original name.
+func (fsys *FilenFS) Rename(oldName string, newName string) error {
+	linkError := func(err error) error {
+		var pathError *fs.PathError
+		if errors.As(err, &pathError) {
+			err = pathError.Err
+		}
+		return &os.LinkError{Op: "rename", Old: oldName, New: newName, Err: err}
+	}
+
+	if cleanName(oldName) == "." {
+		return linkError(errors.New("cannot rename the root directory"))
+	}
+	file, directory, err := fsys.find("rename", oldName, false)
+	if err != nil {
+		return linkError(err)
+	}
+	parentUUID, baseName, err := fsys.findParent("rename", newName)
+	if err != nil {
+		return linkError(err)
+	}
+
+	if file != nil {
+		err = fsys.renameFile(file, parentUUID, baseName)
+	} else {
+		err = fsys.renameDirectory(directory, parentUUID, baseName)
+	}
+	if err != nil {
+		var existsError *filen.ItemExistsError
+		if errors.As(err, &existsError) {
+			err = fs.ErrExist
+		}
+		return linkError(err)
+	}
+	return nil
+}
+
+func (fsys *FilenFS) renameFile(file *filen.File, parentUUID string, name string) error {
+	exists, replacedUUID, err := fsys.backend.FileExists(parentUUID, name)
+	if err != nil {
+		return err
+	}
+	replace := exists && replacedUUID != file.UUID
+
+	// rollback moves the file back to its original directory under its original name
+	originalParentUUID, originalName := file.ParentUUID, file.Name
+	rollback := func(file *filen.File) {
+		file, err := fsys.backend.MoveFile(file, originalParentUUID)
+		if err == nil {
+			_, _ = fsys.backend.RenameFile(file, originalName)
+		}
+	}
+
+	// move first, under a temporary name if the destination contains a file with the current name
+	moved, err := fsys.backend.MoveFile(file, parentUUID)
+	var existsError *filen.ItemExistsError
+	if errors.As(err, &existsError) {
+		file, err = fsys.backend.RenameFile(file, temporaryName())
+		if err != nil {
+			return err
+		}
+		moved, err = fsys.backend.MoveFile(file, parentUUID)
+		if err != nil {
+			rollback(file)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if replace {
+		err = fsys.backend.TrashFile(replacedUUID)
+		if err != nil {
+			rollback(moved)
+			return err
+		}
+	}
+	_, err = fsys.backend.RenameFile(moved, name)
+	if err != nil {
+		if replace {
+			_ = fsys.backend.RestoreFile(replacedUUID)
+		}
+		rollback(moved)
+	}
+	return err
+}
+
+func (fsys *FilenFS) renameDirectory(directory *filen.Directory, parentUUID string, name string) error {
+	exists, existingUUID, err := fsys.backend.DirectoryExists(parentUUID, name)
+	if err != nil {
+		return err
+	}
+	if exists && existingUUID != directory.UUID {
+		return &filen.ItemExistsError{ParentUUID: parentUUID, Name: name, UUID: existingUUID}
+	}
+
+	// rollback moves the directory back to its original parent directory under its original name
+	originalParentUUID, originalName := directory.ParentUUID, directory.Name
+	rollback := func(directory *filen.Directory) {
+		directory, err := fsys.backend.MoveDirectory(directory, originalParentUUID)
+		if err == nil {
+			_, _ = fsys.backend.RenameDirectory(directory, originalName)
+		}
+	}
+
+	// move first, under a temporary name if the destination contains a directory with the current name
+	moved, err := fsys.backend.MoveDirectory(directory, parentUUID)
+	var existsError *filen.ItemExistsError
+	if errors.As(err, &existsError) {
+		directory, err = fsys.backend.RenameDirectory(directory, temporaryName())
+		if err != nil {
+			return err
+		}
+		moved, err = fsys.backend.MoveDirectory(directory, parentUUID)
+		if err != nil {
+			rollback(directory)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fsys.backend.RenameDirectory(moved, name)
+	if err != nil {
+		rollback(moved)
+	}
+	return err
+}
+
+// temporaryName returns a unique name for items that are renamed in several steps.
+func temporaryName() string {
+	return ".rename-" + uuid.NewString()
+}
+
+func (fsys *FilenFS) Stat(name string) (fs.FileInfo, error) {
+	return fsys.ro.Stat(cleanName(name))
+}
+
+func (fsys *FilenFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fsys.ro.ReadDir(cleanName(name))
+}
+
+// readFile is a file or directory opened for reading. It passes through Seek and ReadAt for files.
+type readFile struct {
+	fs.File
+}
+
+func (file *readFile) Write([]byte) (int, error) {
+	info, _ := file.Stat()
+	return 0, &fs.PathError{Op: "write", Path: info.Name(), Err: errors.New("file is opened for reading")}
+}
+
+func (file *readFile) Seek(offset int64, whence int) (int64, error) {
+	seeker, ok := file.File.(io.Seeker)
+	if !ok {
+		return 0, errors.New("cannot seek")
+	}
+	return seeker.Seek(offset, whence)
+}
+
+func (file *readFile) ReadAt(p []byte, off int64) (int, error) {
+	readerAt, ok := file.File.(io.ReaderAt)
+	if !ok {
+		return 0, errors.New("cannot read at offset")
+	}
+	return readerAt.ReadAt(p, off)
+}
+
+// writeFile is a file opened for writing, whose content is piped into an upload.
+type writeFile struct {
+	name     string
+	baseName string
+	writer   *io.PipeWriter
+	size     int64
+	closed   bool
+
+	done chan struct{} // closed when the upload has finished
+	file *filen.File   // the uploaded file
+	err  error         // the error the upload failed with
+}
+
+func (file *writeFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: file.name, Err: errors.New("file is opened for writing")}
+}
+
+func (file *writeFile) Write(p []byte) (int, error) {
+	if file.closed {
+		return 0, &fs.PathError{Op: "write", Path: file.name, Err: fs.ErrClosed}
+	}
+	n, err := file.writer.Write(p)
+	file.size += int64(n)
+	if err != nil {
+		return n, &fs.PathError{Op: "write", Path: file.name, Err: err}
+	}
+	return n, nil
+}
+
+// Close completes the upload and returns any error it failed with.
+func (file *writeFile) Close() error {
+	if file.closed {
+		return &fs.PathError{Op: "close", Path: file.name, Err: fs.ErrClosed}
+	}
+	file.closed = true
+	_ = file.writer.Close()
+	<-file.done
+	if file.err != nil {
+		return &fs.PathError{Op: "close", Path: file.name, Err: file.err}
+	}
+	return nil
+}
+
+// Stat describes the uploaded file once the file has been closed, or the content written so far before that.
+func (file *writeFile) Stat() (fs.FileInfo, error) {
+	if file.closed && file.file != nil {
+		return filenfs.NewFileInfo(file.baseName, file.file, nil), nil
+	}
+	return filenfs.NewFileInfo(file.baseName, &filen.File{Name: file.baseName, Size: file.size, LastModified: time.Now()}, nil), nil
+}
@@ -0,0 +1,426 @@
+package vfs
+
+import (
+	"errors"
+	"fmt"
+	"github.com/FilenCloudDienste/filen-sdk-go/filen"
+	"io"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// memItem is a file or directory kept by a memBackend.
+type memItem struct {
+	file      *filen.File
+	directory *filen.Directory
+	trashed   bool
+}
+
+func (item *memItem) uuid() string {
+	if item.file != nil {
+		return item.file.UUID
+	}
+	return item.directory.UUID
+}
+
+func (item *memItem) parentUUID() string {
+	if item.file != nil {
+		return item.file.ParentUUID
+	}
+	return item.directory.ParentUUID
+}
+
+func (item *memItem) name() string {
+	if item.file != nil {
+		return item.file.Name
+	}
+	return item.directory.Name
+}
+
+// memBackend is a Backend that keeps a drive in memory. Operations can be made to fail.
+type memBackend struct {
+	items    map[string]*memItem // by UUID
+	lastUUID int
+	calls    map[string]int // the number of calls by operation
+	failOn   map[string]int // the call (counting from 1) by operation that fails
+}
+
+var errInjected = errors.New("injected failure")
+
+func newMemBackend() *memBackend {
+	return &memBackend{
+		items: make(map[string]*memItem),
+		calls: make(map[string]int),
+	}
+}
+
+// fail counts a call of an operation and returns an error if the call should fail.
+func (backend *memBackend) fail(op string) error {
+	backend.calls[op]++
+	if backend.failOn[op] == backend.calls[op] {
+		return fmt.Errorf("%s: %w", op, errInjected)
+	}
+	return nil
+}
+
+func (backend *memBackend) newUUID() string {
+	backend.lastUUID++
+	return fmt.Sprintf("uuid-%d", backend.lastUUID)
+}
+
+func (backend *memBackend) addDirectory(parentUUID string, name string) string {
+	directory := &filen.Directory{UUID: backend.newUUID(), Name: name, ParentUUID: parentUUID}
+	backend.items[directory.UUID] = &memItem{directory: directory}
+	return directory.UUID
+}
+
+func (backend *memBackend) addFile(parentUUID string, name string) string {
+	file := &filen.File{UUID: backend.newUUID(), Name: name, Size: 1, Chunks: 1, ParentUUID: parentUUID}
+	backend.items[file.UUID] = &memItem{file: file}
+	return file.UUID
+}
+
+// child returns the item in a directory with the given name that isn't in trash.
+func (backend *memBackend) child(parentUUID string, name string, directory bool) *memItem {
+	for _, item := range backend.items {
+		if !item.trashed && item.parentUUID() == parentUUID && item.name() == name && (item.directory != nil) == directory {
+			return item
+		}
+	}
+	return nil
+}
+
+// paths returns the UUIDs of all items that aren't in trash by their paths.
+func (backend *memBackend) paths() map[string]string {
+	paths := make(map[string]string)
+	for uuid, item := range backend.items {
+		if item.trashed {
+			continue
+		}
+		itemPath := item.name()
+		for parent := backend.items[item.parentUUID()]; parent != nil; parent = backend.items[parent.parentUUID()] {
+			itemPath = path.Join(parent.name(), itemPath)
+		}
+		paths["/"+itemPath] = uuid
+	}
+	return paths
+}
+
+func (backend *memBackend) GetBaseFolderUUID() (string, error) {
+	return "root", nil
+}
+
+func (backend *memBackend) FindItem(path string, requireDirectory bool) (*filen.File, *filen.Directory, error) {
+	segments := filen.ParsePath(path).Segments()
+	currentUUID := "root"
+	for i, segment := range segments {
+		if i == len(segments)-1 && !requireDirectory {
+			if item := backend.child(currentUUID, segment, false); item != nil {
+				file := *item.file
+				return &file, nil, nil
+			}
+		}
+		item := backend.child(currentUUID, segment, true)
+		if item == nil {
+			return nil, nil, nil
+		}
+		currentUUID = item.directory.UUID
+	}
+	if currentUUID == "root" {
+		return nil, &filen.Directory{UUID: "root"}, nil
+	}
+	directory := *backend.items[currentUUID].directory
+	return nil, &directory, nil
+}
+
+func (backend *memBackend) FindItemUUID(path string, requireDirectory bool) (string, error) {
+	file, directory, err := backend.FindItem(path, requireDirectory)
+	if err != nil || (file == nil && directory == nil) {
+		return "", err
+	}
+	if file != nil {
+		return file.UUID, nil
+	}
+	return directory.UUID, nil
+}
+
+func (backend *memBackend) ReadDirectory(uuid string) ([]*filen.File, []*filen.Directory, error) {
+	files := make([]*filen.File, 0)
+	directories := make([]*filen.Directory, 0)
+	for _, item := range backend.items {
+		if item.trashed || item.parentUUID() != uuid {
+			continue
+		}
+		if item.file != nil {
+			file := *item.file
+			files = append(files, &file)
+		} else {
+			directory := *item.directory
+			directories = append(directories, &directory)
+		}
+	}
+	return files, directories, nil
+}
+
+func (backend *memBackend) DownloadFile(file *filen.File, chunkHandler func(chunk int, data []byte) error, _ ...filen.TransferOption) error {
+	return chunkHandler(0, []byte{0})
+}
+
+func (backend *memBackend) FileExists(parentUUID string, name string) (bool, string, error) {
+	if err := backend.fail("FileExists"); err != nil {
+		return false, "", err
+	}
+	if item := backend.child(parentUUID, name, false); item != nil {
+		return true, item.file.UUID, nil
+	}
+	return false, "", nil
+}
+
+func (backend *memBackend) DirectoryExists(parentUUID string, name string) (bool, string, error) {
+	if err := backend.fail("DirectoryExists"); err != nil {
+		return false, "", err
+	}
+	if item := backend.child(parentUUID, name, true); item != nil {
+		return true, item.directory.UUID, nil
+	}
+	return false, "", nil
+}
+
+func (backend *memBackend) CreateDirectory(parentUUID string, name string) (*filen.Directory, error) {
+	if err := backend.fail("CreateDirectory"); err != nil {
+		return nil, err
+	}
+	directory := *backend.items[backend.addDirectory(parentUUID, name)].directory
+	return &directory, nil
+}
+
+func (backend *memBackend) FindDirectoryOrCreate(path string) (string, error) {
+	currentUUID := "root"
+	for _, segment := range filen.ParsePath(path).Segments() {
+		item := backend.child(currentUUID, segment, true)
+		if item == nil {
+			currentUUID = backend.addDirectory(currentUUID, segment)
+		} else {
+			currentUUID = item.directory.UUID
+		}
+	}
+	return currentUUID, nil
+}
+
+func (backend *memBackend) setTrashed(op string, uuid string, trashed bool) error {
+	if err := backend.fail(op); err != nil {
+		return err
+	}
+	item, ok := backend.items[uuid]
+	if !ok || item.trashed == trashed {
+		return fmt.Errorf("%s: no such item: %s", op, uuid)
+	}
+	item.trashed = trashed
+	return nil
+}
+
+func (backend *memBackend) TrashFile(uuid string) error {
+	return backend.setTrashed("TrashFile", uuid, true)
+}
+
+func (backend *memBackend) TrashDirectory(uuid string) error {
+	return backend.setTrashed("TrashDirectory", uuid, true)
+}
+
+func (backend *memBackend) RestoreFile(uuid string) error {
+	return backend.setTrashed("RestoreFile", uuid, false)
+}
+
+// update renames and/or moves an item like [filen.Filen.RenameFile] etc., failing if the name is taken.
+func (backend *memBackend) update(op string, uuid string, parentUUID string, name string) (*memItem, error) {
+	if err := backend.fail(op); err != nil {
+		return nil, err
+	}
+	item := backend.items[uuid]
+	if existing := backend.child(parentUUID, name, item.directory != nil); existing != nil && existing != item {
+		return nil, &filen.ItemExistsError{ParentUUID: parentUUID, Name: name, UUID: existing.uuid()}
+	}
+	if item.file != nil {
+		item.file.ParentUUID, item.file.Name = parentUUID, name
+	} else {
+		item.directory.ParentUUID, item.directory.Name = parentUUID, name
+	}
+	return item, nil
+}
+
+func (backend *memBackend) RenameFile(file *filen.File, name string) (*filen.File, error) {
+	item, err := backend.update("RenameFile", file.UUID, file.ParentUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	renamed := *item.file
+	return &renamed, nil
+}
+
+func (backend *memBackend) RenameDirectory(directory *filen.Directory, name string) (*filen.Directory, error) {
+	item, err := backend.update("RenameDirectory", directory.UUID, directory.ParentUUID, name)
+	if err != nil {
+		return nil, err
+	}
+	renamed := *item.directory
+	return &renamed, nil
+}
+
+func (backend *memBackend) MoveFile(file *filen.File, parentUUID string) (*filen.File, error) {
+	item, err := backend.update("MoveFile", file.UUID, parentUUID, file.Name)
+	if err != nil {
+		return nil, err
+	}
+	moved := *item.file
+	return &moved, nil
+}
+
+func (backend *memBackend) MoveDirectory(directory *filen.Directory, parentUUID string) (*filen.Directory, error) {
+	item, err := backend.update("MoveDirectory", directory.UUID, parentUUID, directory.Name)
+	if err != nil {
+		return nil, err
+	}
+	moved := *item.directory
+	return &moved, nil
+}
+
+func (backend *memBackend) UploadFile(fileName string, parentUUID string, data io.Reader, _ ...filen.TransferOption) (*filen.File, error) {
+	if err := backend.fail("UploadFile"); err != nil {
+		return nil, err
+	}
+	_, err := io.Copy(io.Discard, data)
+	if err != nil {
+		return nil, err
+	}
+	if existing := backend.child(parentUUID, fileName, false); existing != nil {
+		existing.trashed = true
+	}
+	file := *backend.items[backend.addFile(parentUUID, fileName)].file
+	return &file, nil
+}
+
+// newTestBackend returns a memBackend containing
+//
+//	/a.txt      (uuid-1)
+//	/d          (uuid-2), containing a.txt (uuid-3) and b.txt (uuid-4)
+//	/e          (uuid-5)
+func newTestBackend() *memBackend {
+	backend := newMemBackend()
+	backend.addFile("root", "a.txt")
+	d := backend.addDirectory("root", "d")
+	backend.addFile(d, "a.txt")
+	backend.addFile(d, "b.txt")
+	backend.addDirectory("root", "e")
+	return backend
+}
+
+func TestFilenFSRename(t *testing.T) {
+	initial := newTestBackend().paths()
+	tests := []struct {
+		name      string
+		oldName   string
+		newName   string
+		failOn    map[string]int
+		wantErr   error
+		wantPaths map[string]string // nil if the drive should be unchanged
+		wantTrash []string
+	}{
+		{
+			name: "same directory", oldName: "a.txt", newName: "c.txt",
+			wantPaths: map[string]string{"/c.txt": "uuid-1", "/d": "uuid-2", "/d/a.txt": "uuid-3", "/d/b.txt": "uuid-4", "/e": "uuid-5"},
+		},
+		{
+			name: "other directory", oldName: "a.txt", newName: "e/c.txt",
+			wantPaths: map[string]string{"/d": "uuid-2", "/d/a.txt": "uuid-3", "/d/b.txt": "uuid-4", "/e": "uuid-5", "/e/c.txt": "uuid-1"},
+		},
+		{
+			name: "other directory under a temporary name", oldName: "a.txt", newName: "d/c.txt",
+			wantPaths: map[string]string{"/d": "uuid-2", "/d/a.txt": "uuid-3", "/d/b.txt": "uuid-4", "/d/c.txt": "uuid-1", "/e": "uuid-5"},
+		},
+		{
+			name: "replace an existing file", oldName: "a.txt", newName: "d/b.txt",
+			wantPaths: map[string]string{"/d": "uuid-2", "/d/a.txt": "uuid-3", "/d/b.txt": "uuid-1", "/e": "uuid-5"},
+			wantTrash: []string{"uuid-4"},
+		},
+		{
+			name: "directory", oldName: "e", newName: "d/f",
+			wantPaths: map[string]string{"/a.txt": "uuid-1", "/d": "uuid-2", "/d/a.txt": "uuid-3", "/d/b.txt": "uuid-4", "/d/f": "uuid-5"},
+		},
+		{
+			name: "existing directory", oldName: "e", newName: "d",
+			wantErr: fs.ErrExist,
+		},
+		{
+			name: "move fails", oldName: "a.txt", newName: "e/c.txt",
+			failOn:  map[string]int{"MoveFile": 1},
+			wantErr: errInjected,
+		},
+		{
+			name: "move under a temporary name fails", oldName: "a.txt", newName: "d/c.txt",
+			failOn:  map[string]int{"MoveFile": 2},
+			wantErr: errInjected,
+		},
+		{
+			name: "trashing the replaced file fails", oldName: "a.txt", newName: "d/b.txt",
+			failOn:  map[string]int{"TrashFile": 1},
+			wantErr: errInjected,
+		},
+		{
+			name: "final rename fails", oldName: "a.txt", newName: "e/c.txt",
+			failOn:  map[string]int{"RenameFile": 1},
+			wantErr: errInjected,
+		},
+		{
+			name: "final rename under a temporary name fails", oldName: "a.txt", newName: "d/c.txt",
+			failOn:  map[string]int{"RenameFile": 2},
+			wantErr: errInjected,
+		},
+		{
+			name: "final rename replacing a file fails", oldName: "a.txt", newName: "d/b.txt",
+			failOn:  map[string]int{"RenameFile": 1},
+			wantErr: errInjected,
+		},
+		{
+			name: "final rename of a directory fails", oldName: "e", newName: "d/f",
+			failOn:  map[string]int{"RenameDirectory": 1},
+			wantErr: errInjected,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := newTestBackend()
+			backend.failOn = test.failOn
+			fsys := NewFilenFS(backend, "")
+			err := fsys.Rename(test.oldName, test.newName)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("err = %v, want %v", err, test.wantErr)
+			}
+			wantPaths := test.wantPaths
+			if wantPaths == nil {
+				wantPaths = initial
+			}
+			if paths := backend.paths(); !maps.Equal(paths, wantPaths) {
+				t.Errorf("paths = %v, want %v", paths, wantPaths)
+			}
+			var trash []string
+			for uuid, item := range backend.items {
+				if item.trashed {
+					trash = append(trash, uuid)
+				}
+			}
+			slices.Sort(trash)
+			if !slices.Equal(trash, test.wantTrash) {
+				t.Errorf("trash = %v, want %v", trash, test.wantTrash)
+			}
+			for path := range backend.paths() {
+				if strings.Contains(path, ".rename-") {
+					t.Errorf("item left under a temporary name: %s", path)
+				}
+			}
+		})
+	}
+}
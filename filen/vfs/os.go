@@ -0,0 +1,68 @@
+package vfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// OSFS is an [FS] on a directory of the local file system, e.g. to test code written against FS.
+type OSFS struct {
+	dir string
+}
+
+var _ FS = (*OSFS)(nil)
+
+// NewOSFS creates an OSFS for a local directory.
+func NewOSFS(dir string) *OSFS {
+	return &OSFS{dir}
+}
+
+// localPath converts a name to a path on the local file system.
+func (fsys *OSFS) localPath(name string) string {
+	return filepath.Join(fsys.dir, filepath.FromSlash(cleanName(name)))
+}
+
+func (fsys *OSFS) Open(name string) (File, error) {
+	file, err := os.Open(fsys.localPath(name))
+	if err != nil {
+		return nil, err // avoid returning a nil *os.File as a non-nil File
+	}
+	return file, nil
+}
+
+func (fsys *OSFS) Create(name string) (File, error) {
+	file, err := os.Create(fsys.localPath(name))
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (fsys *OSFS) Mkdir(name string) error {
+	return os.Mkdir(fsys.localPath(name), 0777)
+}
+
+func (fsys *OSFS) MkdirAll(name string) error {
+	return os.MkdirAll(fsys.localPath(name), 0777)
+}
+
+func (fsys *OSFS) Remove(name string) error {
+	return os.Remove(fsys.localPath(name))
+}
+
+func (fsys *OSFS) RemoveAll(name string) error {
+	return os.RemoveAll(fsys.localPath(name))
+}
+
+func (fsys *OSFS) Rename(oldName string, newName string) error {
+	return os.Rename(fsys.localPath(oldName), fsys.localPath(newName))
+}
+
+func (fsys *OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(fsys.localPath(name))
+}
+
+func (fsys *OSFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(fsys.localPath(name))
+}
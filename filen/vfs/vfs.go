@@ -0,0 +1,55 @@
+// Package vfs defines a writable file system interface, so that the same code can work
+// with a Filen drive ([FilenFS]) or a directory on the local file system ([OSFS]).
+package vfs
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// FS is a writable file system.
+//
+// Names are slash-separated paths relative to the root of the file system; leading slashes, "." and ".." are cleaned.
+// Errors are reported as [*fs.PathError] (or [*os.LinkError] for Rename) where possible,
+// wrapping [fs.ErrNotExist], [fs.ErrExist] etc. like the functions of package os.
+type FS interface {
+	// Open opens the named file or directory for reading.
+	Open(name string) (File, error)
+	// Create creates the named file for writing, replacing it if it already exists.
+	// The parent directory must exist.
+	Create(name string) (File, error)
+	// Mkdir creates the named directory. The parent directory must exist.
+	Mkdir(name string) error
+	// MkdirAll creates the named directory along with any missing parents.
+	MkdirAll(name string) error
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// RemoveAll removes the named file or directory with all its content.
+	// It returns nil if the item doesn't exist.
+	RemoveAll(name string) error
+	// Rename moves and/or renames an item, replacing an existing file at newName.
+	Rename(oldName string, newName string) error
+	// Stat returns a [fs.FileInfo] describing the named file or directory.
+	Stat(name string) (fs.FileInfo, error)
+	// ReadDir reads the named directory and returns its entries sorted by name.
+	ReadDir(name string) ([]fs.DirEntry, error)
+}
+
+// File is a file or directory opened by an [FS], either for reading (Open) or for writing (Create).
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+	Stat() (fs.FileInfo, error)
+}
+
+// cleanName cleans a name and converts it to the form used by [fs.FS] ("." for the root).
+func cleanName(name string) string {
+	name = strings.TrimPrefix(path.Clean("/"+name), "/")
+	if name == "" {
+		return "."
+	}
+	return name
+}
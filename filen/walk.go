@@ -0,0 +1,285 @@
+package filen
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// A WalkEntry is a file or directory visited by [Filen.Walk] or [Filen.WalkUUID].
+type WalkEntry struct {
+	Path      string     // the full cloud path of the item, e.g. "/Documents/report.pdf"
+	Depth     int        // the depth relative to the root of the walk (0 for the root itself)
+	File      *File      // the file, if the item is a file
+	Directory *Directory // the directory, if the item is a directory
+}
+
+// IsDir returns whether the entry is a directory.
+func (entry *WalkEntry) IsDir() bool {
+	return entry.Directory != nil
+}
+
+// Name returns the name of the file or directory.
+func (entry *WalkEntry) Name() string {
+	if entry.Directory != nil {
+		return entry.Directory.Name
+	}
+	return entry.File.Name
+}
+
+// A WalkFunc is called by [Filen.Walk] and [Filen.WalkUUID] for every visited item, following the semantics of [fs.WalkDirFunc]:
+//
+// The function is called for a directory before its content is read. If reading the content fails,
+// it is called a second time for that directory with the error. Returning [fs.SkipDir] skips the
+// directory's content (or, for a file, the remaining items in its directory), returning [fs.SkipAll]
+// ends the walk without error, and returning any other error ends the walk with that error.
+type WalkFunc func(entry *WalkEntry, err error) error
+
+// A WalkOption configures [Filen.Walk] and [Filen.WalkUUID].
+type WalkOption func(options *walkOptions)
+
+type walkOptions struct {
+	maxDepth    int
+	concurrency int
+}
+
+// WithMaxDepth limits a walk to items at most maxDepth levels below its root.
+func WithMaxDepth(maxDepth int) WalkOption {
+	return func(options *walkOptions) {
+		options.maxDepth = maxDepth
+	}
+}
+
+// WithConcurrency makes a walk read up to concurrency directories at the same time.
+// The WalkFunc is then called concurrently from multiple goroutines, and the order in which
+// directories are visited is not deterministic (the items within a directory are still visited in order).
+func WithConcurrency(concurrency int) WalkOption {
+	return func(options *walkOptions) {
+		options.concurrency = concurrency
+	}
+}
+
+// Walk walks the cloud drive starting at the item with the path root (see [Filen.FindItem]),
+// calling fn for every file and directory, similar to [fs.WalkDir].
+// Items within a directory are visited in lexical order.
+func (filen *Filen) Walk(root string, fn WalkFunc, opts ...WalkOption) error {
+	rootEntry, err := filen.walkRoot(root)
+	if err != nil {
+		return err
+	}
+	return filen.walk(rootEntry, fn, opts)
+}
+
+// WalkUUID is like [Filen.Walk], but starts at the directory with the given UUID.
+func (filen *Filen) WalkUUID(uuid string, fn WalkFunc, opts ...WalkOption) error {
+	directory, err := filen.GetDirectory(uuid)
+	if err != nil {
+		return err
+	}
+	directoryPath, err := filen.GetDirectoryPath(uuid)
+	if err != nil {
+		return err
+	}
+	return filen.walk(&WalkEntry{Path: directoryPath, Directory: directory}, fn, opts)
+}
+
+// walk walks the cloud drive starting at the given root entry.
+func (filen *Filen) walk(rootEntry *WalkEntry, fn WalkFunc, opts []WalkOption) error {
+	options := &walkOptions{concurrency: 1}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	walker := &walker{filen: filen, fn: fn, options: options}
+	err := walker.fn(rootEntry, nil)
+	if err != nil || !rootEntry.IsDir() {
+		if errors.Is(err, fs.SkipDir) || errors.Is(err, fs.SkipAll) {
+			return nil
+		}
+		return err
+	}
+
+	if options.concurrency <= 1 {
+		err = walker.walkSequential(rootEntry)
+	} else {
+		err = walker.walkConcurrent(rootEntry)
+	}
+	if errors.Is(err, fs.SkipAll) {
+		return nil
+	}
+	return err
+}
+
+// walkRoot resolves the root path of a walk.
+func (filen *Filen) walkRoot(root string) (*WalkEntry, error) {
+	rootPath := ParsePath(root)
+	if rootPath.IsRoot() {
+		baseFolderUUID, err := filen.GetBaseFolderUUID()
+		if err != nil {
+			return nil, err
+		}
+		return &WalkEntry{Path: rootPath.String(), Directory: &Directory{UUID: baseFolderUUID}}, nil
+	}
+	file, directory, err := filen.FindItem(rootPath.String(), false)
+	if err != nil {
+		return nil, err
+	}
+	if file == nil && directory == nil {
+		return nil, fmt.Errorf("no such item: %s", rootPath)
+	}
+	return &WalkEntry{Path: rootPath.String(), File: file, Directory: directory}, nil
+}
+
+type walker struct {
+	filen   *Filen
+	fn      WalkFunc
+	options *walkOptions
+}
+
+// readEntries reads the content of a directory entry as entries sorted by name.
+// It returns nil if the directory is at the maximum depth.
+func (walker *walker) readEntries(parent *WalkEntry) ([]*WalkEntry, error) {
+	if walker.options.maxDepth > 0 && parent.Depth >= walker.options.maxDepth {
+		return nil, nil
+	}
+	files, directories, err := walker.filen.ReadDirectory(parent.Directory.UUID)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*WalkEntry, 0, len(files)+len(directories))
+	for _, file := range files {
+		entries = append(entries, &WalkEntry{Path: joinCloudPath(parent.Path, file.Name), Depth: parent.Depth + 1, File: file})
+	}
+	for _, directory := range directories {
+		entries = append(entries, &WalkEntry{Path: joinCloudPath(parent.Path, directory.Name), Depth: parent.Depth + 1, Directory: directory})
+	}
+	slices.SortStableFunc(entries, func(a, b *WalkEntry) int { return strings.Compare(a.Name(), b.Name()) })
+	return entries, nil
+}
+
+// visitContent visits the content of a directory whose entry has already been visited
+// and returns the subdirectories that should be descended into (used for concurrent walks).
+// It stops without visiting further items once stopped is closed.
+func (walker *walker) visitContent(parent *WalkEntry, stopped <-chan struct{}) ([]*WalkEntry, error) {
+	isStopped := func() bool {
+		select {
+		case <-stopped:
+			return true
+		default:
+			return false
+		}
+	}
+
+	if isStopped() {
+		return nil, nil
+	}
+	entries, err := walker.readEntries(parent)
+	if err != nil {
+		if isStopped() {
+			return nil, nil
+		}
+		err = walker.fn(parent, err)
+		if errors.Is(err, fs.SkipDir) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	descend := make([]*WalkEntry, 0)
+	for _, entry := range entries {
+		if isStopped() {
+			return nil, nil
+		}
+		err := walker.fn(entry, nil)
+		if errors.Is(err, fs.SkipDir) {
+			if entry.IsDir() {
+				continue
+			}
+			break // skip the remaining items in the directory
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entry.IsDir() {
+			descend = append(descend, entry)
+		}
+	}
+	return descend, nil
+}
+
+// walkSequential walks the content of a directory whose entry has already been visited, depth first.
+func (walker *walker) walkSequential(directory *WalkEntry) error {
+	entries, err := walker.readEntries(directory)
+	if err != nil {
+		err = walker.fn(directory, err)
+		if errors.Is(err, fs.SkipDir) {
+			return nil
+		}
+		return err
+	}
+
+	for _, entry := range entries {
+		err := walker.fn(entry, nil)
+		if errors.Is(err, fs.SkipDir) {
+			if entry.IsDir() {
+				continue
+			}
+			return nil // skip the remaining items in the directory
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			err = walker.walkSequential(entry)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// walkConcurrent walks the content of a directory whose entry has already been visited,
+// reading multiple directories at the same time.
+func (walker *walker) walkConcurrent(root *WalkEntry) error {
+	sem := make(chan int, walker.options.concurrency)
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	stopped := make(chan struct{}) // closed when the walk ends early
+
+	var walkDirectory func(directory *WalkEntry)
+	walkDirectory = func(directory *WalkEntry) {
+		defer wg.Done()
+		select {
+		case sem <- 1:
+		case <-stopped:
+			return
+		}
+		subdirectories, err := walker.visitContent(directory, stopped)
+		<-sem
+		if err != nil {
+			errOnce.Do(func() {
+				firstErr = err
+				close(stopped)
+			})
+			return
+		}
+		for _, subdirectory := range subdirectories {
+			wg.Add(1)
+			go walkDirectory(subdirectory)
+		}
+	}
+	wg.Add(1)
+	walkDirectory(root)
+	wg.Wait()
+	return firstErr
+}
+
+// joinCloudPath appends a name to a cloud path.
+func joinCloudPath(parentPath string, name string) string {
+	return strings.TrimSuffix(parentPath, "/") + "/" + name
+}
@@ -0,0 +1,84 @@
+package filen
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestWalkCache returns a Filen whose metadata cache contains the directories with the given files below the root,
+// so that walks don't need to make requests.
+func newTestWalkCache(t *testing.T, directories map[string][]string) *Filen {
+	filen := &Filen{}
+	err := filen.SetCacheOptions(CacheOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootDirectories := make([]*Directory, 0)
+	for name, fileNames := range directories {
+		rootDirectories = append(rootDirectories, &Directory{UUID: "uuid-" + name, Name: name, ParentUUID: "root"})
+		files := make([]*File, 0)
+		for _, fileName := range fileNames {
+			files = append(files, &File{UUID: "uuid-" + name + "/" + fileName, Name: fileName, ParentUUID: "uuid-" + name})
+		}
+		filen.cache.putListing("uuid-"+name, files, nil)
+	}
+	filen.cache.putListing("root", nil, rootDirectories)
+	filen.cache.putDirectoryUUID("/", "root")
+	return filen
+}
+
+func TestWalkPathThatLooksLikeUUID(t *testing.T) {
+	name := "d41d8cd98f00b204e9800998ecf8427e" // an MD5 hash, which uuid.Validate accepts
+	filen := newTestWalkCache(t, map[string][]string{name: {"f"}})
+	var paths []string
+	err := filen.Walk(name, func(entry *WalkEntry, err error) error {
+		paths = append(paths, entry.Path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/" + name, "/" + name + "/f"}; !slices.Equal(paths, want) {
+		t.Errorf("visited %v, want %v", paths, want)
+	}
+}
+
+func TestWalkConcurrentStopsVisiting(t *testing.T) {
+	fileNames := make([]string, 50)
+	for i := range fileNames {
+		fileNames[i] = fmt.Sprintf("f%02d", i)
+	}
+	filen := newTestWalkCache(t, map[string][]string{"a": fileNames, "b": fileNames})
+
+	// the walk of a ends with an error while the walk of b is visiting its files
+	errStop := errors.New("stop")
+	visitingB := make(chan struct{})
+	var once sync.Once
+	var mu sync.Mutex
+	visitedB := 0
+	err := filen.Walk("/", func(entry *WalkEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if entry.File.ParentUUID == "uuid-a" {
+			<-visitingB
+			return errStop
+		}
+		once.Do(func() { close(visitingB) })
+		mu.Lock()
+		visitedB++
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		return nil
+	}, WithConcurrency(2))
+	if !errors.Is(err, errStop) {
+		t.Fatalf("err = %v, want %v", err, errStop)
+	}
+	if visitedB == len(fileNames) {
+		t.Errorf("visited all %d files of b after the walk has ended", visitedB)
+	}
+}
@@ -5,4 +5,5 @@ go 1.22
 require (
 	github.com/google/uuid v1.6.0
 	golang.org/x/crypto v0.26.0
+	golang.org/x/text v0.17.0
 )